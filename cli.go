@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mladshij/createGZPlDoc/pldoc"
+)
+
+// Коды завершения программы
+const (
+	exitOK     = 0 // все документы обработаны
+	exitFailed = 1 // часть документов не обработана или выходной файл не сохранён
+	exitUsage  = 2 // неверные параметры или файлы настроек
+)
+
+// Уровни подробности вывода
+const (
+	levelQuiet   = 0 // только ошибки и итоговая строка
+	levelNormal  = 1
+	levelVerbose = 2 // сведения о каждом документе
+)
+
+var verbosity = levelNormal
+
+// Параметры командной строки
+type options struct {
+	inputDir         string
+	files            []string
+	recursive        bool
+	include          stringList
+	exclude          stringList
+	roomsFileName    string
+	accountsFileName string
+	outFileName      string
+	baseFileName     string
+	layoutsFileName  string
+	profileName      string
+	servicesFileName string
+	premisesFileName string
+	registryFileName string
+	housesFileName   string
+	basisFileName    string
+	readingsFileName string
+	normsFileName    string
+	problemsFileName string
+	reportFileName   string
+	period           string
+	infoSource       string
+	splitHouses      bool
+	fresh            bool
+	lenient          bool
+	tolerance        pldoc.Tolerance
+}
+
+func parseOptions() *options {
+	var verbose, quiet bool
+
+	opts := &options{tolerance: pldoc.DefaultTolerance()}
+
+	flag.StringVar(&opts.inputDir, "in", "./In/", "каталог с платёжными документами (.xls, .xlsx, .xlsm)")
+	flag.BoolVar(&opts.recursive, "r", false, "просматривать вложенные каталоги")
+	flag.Var(&opts.include, "include", "шаблон имён входных файлов (можно указать несколько раз или через запятую)")
+	flag.Var(&opts.exclude, "exclude", "шаблон имён файлов, которые не обрабатываются (можно указать несколько раз или через запятую)")
+	flag.StringVar(&opts.roomsFileName, "rooms", "Rooms.xlsx", "реестр помещений, выгруженный из ГИС ЖКХ")
+	flag.StringVar(&opts.accountsFileName, "accounts", "Accounts.xlsx", "реестр ЕЛС, выгруженный из ГИС ЖКХ")
+	flag.StringVar(&opts.outFileName, "out", "PDTemplate.xlsx", "выходной файл шаблона импорта ПД")
+	flag.StringVar(&opts.baseFileName, "base", "", "официальный шаблон импорта ПД ГИС ЖКХ (xlsx), на основе которого создаётся выходной файл")
+	flag.StringVar(&opts.layoutsFileName, "layouts", "", "файл профилей расположения полей (YAML/JSON)")
+	flag.StringVar(&opts.profileName, "profile", "", "профиль расположения полей (по умолчанию выбирается по имени файла)")
+	flag.StringVar(&opts.servicesFileName, "services", "", "файл соответствия услуг (YAML/JSON)")
+	flag.StringVar(&opts.premisesFileName, "premises", "", "файл правил распознавания помещений (YAML/JSON)")
+	flag.StringVar(&opts.registryFileName, "registry", "", "файл расположения данных в реестрах ГИС ЖКХ (YAML/JSON)")
+	flag.StringVar(&opts.housesFileName, "houses", "", "файл списка домов (YAML/JSON), если обслуживается несколько домов")
+	flag.BoolVar(&opts.splitHouses, "split-houses", false, "отдельный выходной файл для каждого дома (к имени -out добавляется код дома)")
+	flag.StringVar(&opts.basisFileName, "recalculations", "", "файл правил определения оснований перерасчётов (YAML/JSON)")
+	flag.StringVar(&opts.readingsFileName, "readings", "", "показания приборов учета (.csv или .xlsx с колонками \"Помещение\", \"Услуга\", \"Показания\")")
+	flag.StringVar(&opts.normsFileName, "norms", "", "файл нормативов потребления по услугам (YAML/JSON)")
+	flag.StringVar(&opts.problemsFileName, "errors", "", "файл для списка ошибок (.csv или .xlsx с листом \"Ошибки\")")
+	flag.StringVar(&opts.reportFileName, "report", "", "файл итогового отчёта по обработанным документам (.json или .csv)")
+	flag.StringVar(&opts.period, "period", "", "расчётный период для всех документов (ММ.ГГГГ или \"сентябрь 2019\"), по умолчанию берётся из документа")
+	flag.StringVar(&opts.infoSource, "premises-info", pldoc.InfoFromDocument,
+		"источник площадей и количества проживающих при расхождении документа и реестра помещений (document, registry)")
+	flag.BoolVar(&opts.fresh, "fresh", false, "начать с чистого шаблона вместо дополнения существующего файла")
+	flag.BoolVar(&opts.lenient, "lenient", false, "пропускать документы без идентификаторов вместо завершения с ошибкой")
+	flag.Var(&opts.tolerance.Total, "tolerance", "допустимое расхождение суммы строк и итога документа, руб.")
+	flag.Var(&opts.tolerance.Charge, "charge-tolerance", "допустимое расхождение объём*тариф и начисления по услуге, руб.")
+	flag.BoolVar(&verbose, "v", false, "подробный вывод")
+	flag.BoolVar(&quiet, "q", false, "выводить только ошибки и итоговую строку")
+
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Формирование шаблона импорта платёжных документов в ГИС ЖКХ\n\n")
+		fmt.Fprintf(out, "Использование: %s [параметры] [файл, каталог или шаблон имён ...]\n\n", os.Args[0])
+		fmt.Fprintf(out, "Если файлы не указаны, то обрабатываются все .xls, .xlsx, .xlsm из каталога -in.\n")
+		fmt.Fprintf(out, "Файлы обрабатываются в порядке сортировки их путей.\n\n")
+		fmt.Fprintf(out, "Параметры:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(out, "\nКоды завершения: %d - успешно, %d - часть документов не обработана, %d - неверные параметры.\n",
+			exitOK, exitFailed, exitUsage)
+	}
+	flag.Parse()
+
+	opts.files = flag.Args()
+	switch {
+	case quiet:
+		verbosity = levelQuiet
+	case verbose:
+		verbosity = levelVerbose
+	}
+	return opts
+}
+
+// Список значений параметра, который можно указать несколько раз или через запятую
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// Вывод сообщения с учётом уровня подробности
+func logf(level int, format string, args ...interface{}) {
+	if verbosity >= level {
+		fmt.Printf(format, args...)
+	}
+}
+
+// Вывод ошибки (выводится всегда)
+func errorf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
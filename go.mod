@@ -0,0 +1,12 @@
+module github.com/mladshij/createGZPlDoc
+
+go 1.26.0
+
+require (
+	github.com/extrame/xls v0.0.1
+	github.com/tealeg/xlsx v1.0.5
+	golang.org/x/text v0.42.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require github.com/extrame/ole2 v0.0.0-20160812065207-d69429661ad7 // indirect
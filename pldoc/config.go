@@ -0,0 +1,25 @@
+package pldoc
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Читает файл настроек в формате YAML или JSON (JSON является подмножеством YAML)
+func loadConfigFile(fileName string, v interface{}) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, v)
+}
+
+// Накладывает значения из произвольного узла настроек на уже заполненную структуру
+func mergeConfigNode(node interface{}, v interface{}) error {
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	return yaml.UnmarshalStrict(data, v)
+}
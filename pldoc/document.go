@@ -0,0 +1,146 @@
+// Пакет pldoc содержит модель платёжного документа, разбор платёжных документов
+// из биллинговой программы (.xls) и формирование шаблона импорта ПД в ГИС ЖКХ.
+package pldoc
+
+import "fmt"
+
+const (
+	RoomTypeLive   = 1 // жилое помещение (квартира)
+	RoomTypeOffice = 2 // нежилое помещение (офис)
+)
+
+// Идентификация помещения
+type RoomID struct {
+	House  string // ключ дома (см. House.Key), пустой, если дома не заданы
+	Number int
+	Suffix string // литера и (или) дробная часть номера: "а", "/1", "а/1"
+	Room   int    // номер комнаты в квартире (коммунальной), 0 - вся квартира
+	Type   int
+}
+
+func (room RoomID) String() string {
+	res := fmt.Sprintf("room %d%s", room.Number, room.Suffix)
+	if room.Type == RoomTypeOffice {
+		res = fmt.Sprintf("office %d%s", room.Number, room.Suffix)
+	}
+	if room.Room != 0 {
+		res += fmt.Sprintf(" (комн. %d)", room.Room)
+	}
+	if room.House != "" {
+		res = fmt.Sprintf("house %s, %s", room.House, res)
+	}
+	return res
+}
+
+// Платёжный документ за один расчётный период по одному лицевому счёту
+type PaymentDocument struct {
+	SourceFile string // исходный файл из биллинговой программы
+
+	Period Period // расчётный период
+
+	Account    string // номер лицевого счёта в биллинговой программе
+	Room       RoomID // помещение
+	PremisesID string // Идентификатор помещения (ГИС ЖКХ)
+	ZhkuID     string // Идентификатор ЖКУ (ГИС ЖКХ)
+
+	PremisesInfo // площади и количество проживающих
+
+	Balance     Money // задолженность (больше 0) или аванс (меньше 0) на начало расчётного периода
+	PaymentsDay int   // учтены платежи, поступившие до указанного числа расчётного периода включительно
+
+	BIK         string // БИК банка
+	BankAccount string // расчётный счёт
+
+	Services      []ServiceLine // строки услуг (кроме неустоек)
+	Maintenance   Maintenance   // плата за содержание жилого помещения
+	Penalties     []Penalty     // неустойки (пени)
+	CapitalRepair CapitalRepair // взнос на капитальный ремонт
+
+	Total Money // итого к оплате по документу
+
+	warnings []parseWarning // предупреждения разбора документа
+}
+
+// Задолженность за предыдущие периоды
+func (doc *PaymentDocument) Debt() Money {
+	if doc.Balance > 0 {
+		return doc.Balance
+	}
+	return 0
+}
+
+// Аванс на начало расчётного периода
+func (doc *PaymentDocument) Advance() Money {
+	if doc.Balance < 0 {
+		return -doc.Balance
+	}
+	return 0
+}
+
+// Характеристики помещения (0 - значение неизвестно)
+type PremisesInfo struct {
+	Area       float64 // общая площадь, кв.м.
+	LivingArea float64 // жилая площадь, кв.м.
+	HeatedArea float64 // отапливаемая площадь, кв.м.
+	Residents  int     // количество проживающих
+}
+
+// Строка услуги из платёжного документа
+type ServiceLine struct {
+	Name        string       // название услуги в биллинговой программе
+	ServiceName string       // название услуги в таблице соответствия (без уточнения в скобках)
+	GisName     string       // название услуги в ГИС ЖКХ
+	Unit        string       // единица измерения
+	Method      string       // способ определения объемов КУ
+	Group       ServiceGroup // группа колонок в шаблоне
+
+	Volume        float64 // объём, площадь, количество
+	Price         Price   // тариф
+	Charged       Money   // всего начислено за расчётный период
+	Recalculation Money   // перерасчёт
+	Total         Money   // к оплате
+
+	Comment            string // комментарий к строке в биллинговой программе
+	RecalculationBasis string // основание перерасчёта (ГИС ЖКХ)
+
+	Norm       float64 // норматив потребления (0 - не задан)
+	Reading    float64 // текущие показания прибора учета
+	HasReading bool
+}
+
+// Индивидуальное потребление (иначе - содержание общего имущества)
+func (line *ServiceLine) Individual() bool {
+	return line.Group != GroupCommon
+}
+
+// Дополнительная услуга
+func (line *ServiceLine) Additional() bool {
+	return line.Group == GroupAdditional
+}
+
+// Плата за содержание жилого помещения (текущее содержание и услуги за ОИ)
+type Maintenance struct {
+	Price Price // размер платы на кв. м
+	Total Money // всего
+}
+
+// Неустойка (пени)
+type Penalty struct {
+	Kind   string // вид начисления
+	Basis  string // основания начислений
+	Amount Money  // сумма, руб.
+}
+
+// Сведения о взносе на капитальный ремонт
+type CapitalRepair struct {
+	Rate             Price // размер взноса на кв.м, руб.
+	Charged          Money // всего начислено за расчётный период
+	Recalculation    Money // перерасчёты всего
+	HasRecalculation bool  // перерасчёт указан в документе
+	Total            Money // итого к оплате за расчётный период
+}
+
+// Номер платёжного документа (ГГММ+номер лицевого счёта)
+func (doc *PaymentDocument) Number() string {
+	return doc.Period.NumberPrefix() + doc.Account
+}
@@ -0,0 +1,83 @@
+package pldoc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ошибки разбора платёжных документов и реестров.
+// Возвращаются обёрнутыми в *ParseError, проверяются через errors.Is.
+var (
+	ErrSheetNotFound         = errors.New("sheet not found")
+	ErrPeriodNotFound        = errors.New("period not found")
+	ErrInvalidPeriod         = errors.New("invalid period")
+	ErrAccountNotFound       = errors.New("account not found")
+	ErrAddressNotFound       = errors.New("address not found")
+	ErrMissingRoomID         = errors.New("premises number not found")
+	ErrHouseNotFound         = errors.New("house not found")
+	ErrAreaNotFound          = errors.New("area not found")
+	ErrBankAccountNotFound   = errors.New("bank account not found")
+	ErrBIKNotFound           = errors.New("BIK not found")
+	ErrCapitalRepairNotFound = errors.New("capital repair info not found")
+	ErrTotalNotFound         = errors.New("total sum not found")
+	ErrBalanceNotFound       = errors.New("balance not found")
+	ErrServicesNotFound      = errors.New("service list not found")
+	ErrUnknownService        = errors.New("unknown service")
+	ErrInvalidNumber         = errors.New("invalid number")
+	ErrMissingPremisesID     = errors.New("premises not found in rooms registry")
+	ErrDuplicatePremises     = errors.New("premises has several IDs in rooms registry")
+	ErrMissingZhkuID         = errors.New("premises not found in accounts registry")
+	ErrDuplicateAccount      = errors.New("premises has several accounts in accounts registry")
+	ErrClosedAccount         = errors.New("account is closed")
+)
+
+// Ошибка с указанием места во входном файле.
+// Row и Col - номера строки и колонки с 0 (-1, если место неизвестно).
+type ParseError struct {
+	File  string
+	Sheet string
+	Row   int
+	Col   int
+	Text  string // исходный текст ячейки
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	var parts []string
+	if e.File != "" {
+		parts = append(parts, e.File)
+	}
+	if e.Sheet != "" {
+		parts = append(parts, "sheet "+e.Sheet)
+	}
+	if e.Row >= 0 && e.Col >= 0 {
+		parts = append(parts, fmt.Sprintf("row %d, col %d", e.Row+1, e.Col+1))
+	} else if e.Row >= 0 {
+		parts = append(parts, fmt.Sprintf("row %d", e.Row+1))
+	}
+	msg := e.Err.Error()
+	if e.Text != "" {
+		msg += fmt.Sprintf(" in '%s'", e.Text)
+	}
+	return strings.Join(append(parts, msg), ": ")
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Ошибка в ячейке row, col
+func cellError(row int, col int, text string, err error) *ParseError {
+	return &ParseError{Row: row, Col: col, Text: text, Err: err}
+}
+
+// Ошибка, не связанная с определённой ячейкой
+func docError(err error) *ParseError {
+	return &ParseError{Row: -1, Col: -1, Err: err}
+}
+
+// Ошибка разбора числа в ячейке row, col
+func numberError(row int, col int, text string) *ParseError {
+	return cellError(row, col, text, ErrInvalidNumber)
+}
@@ -0,0 +1,146 @@
+package pldoc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Дом, обслуживаемый управляющей организацией
+type House struct {
+	Code    string `yaml:"code"`           // код дома (используется в именах выходных файлов)
+	FIAS    string `yaml:"fias,omitempty"` // ГУИД дома по ФИАС (ГИС ЖКХ)
+	Address string `yaml:"address"`        // регулярное выражение для адреса дома (обязательно)
+
+	re *regexp.Regexp
+}
+
+// Ключ дома в идентификации помещения: ГУИД по ФИАС, если задан, иначе код
+func (h *House) Key() string {
+	if h.FIAS != "" {
+		return h.FIAS
+	}
+	return h.Code
+}
+
+// Дома, обслуживаемые управляющей организацией. Если список пуст, то считается,
+// что все помещения находятся в одном доме (дом в идентификации помещения не указывается).
+type Houses []*House
+
+// Читает список домов из файла (YAML или JSON)
+func LoadHouses(fileName string) (Houses, error) {
+	var houses Houses
+
+	if err := loadConfigFile(fileName, &houses); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	if err := houses.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return houses, nil
+}
+
+func (houses Houses) compile() error {
+	codes := make(map[string]bool)
+	for _, h := range houses {
+		if h.Code == "" {
+			return fmt.Errorf("house code is empty")
+		}
+		if codes[h.Code] {
+			return fmt.Errorf("house %s: duplicate code", h.Code)
+		}
+		codes[h.Code] = true
+		// дом платёжного документа определяется только по адресу
+		if h.Address == "" {
+			return fmt.Errorf("house %s: address expected", h.Code)
+		}
+		re, err := regexp.Compile(h.Address)
+		if err != nil {
+			return fmt.Errorf("house %s: %v", h.Code, err)
+		}
+		h.re = re
+	}
+	return nil
+}
+
+// Дом по адресу
+func (houses Houses) MatchAddress(address string) (*House, bool) {
+	for _, h := range houses {
+		if h.re != nil && h.re.MatchString(address) {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// Дом по ГУИД ФИАС
+func (houses Houses) ByFIAS(fias string) (*House, bool) {
+	fias = strings.TrimSpace(fias)
+	for _, h := range houses {
+		if h.FIAS != "" && strings.EqualFold(h.FIAS, fias) {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// Дом по коду, ГУИД ФИАС или адресу
+func (houses Houses) Find(s string) (*House, bool) {
+	for _, h := range houses {
+		if h.Code == s {
+			return h, true
+		}
+	}
+	if h, ok := houses.ByFIAS(s); ok {
+		return h, true
+	}
+	return houses.MatchAddress(s)
+}
+
+// Дом по ключу из идентификации помещения
+func (houses Houses) ByKey(key string) (*House, bool) {
+	for _, h := range houses {
+		if h.Key() == key {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// Документы одного дома
+type HouseDocuments struct {
+	House *House // nil для помещений без дома
+	Docs  []*PaymentDocument
+}
+
+// Группирует документы по домам в порядке списка домов; документы помещений
+// без дома (или с неизвестным домом) образуют первую группу с House == nil
+func (houses Houses) Group(docs []*PaymentDocument) []HouseDocuments {
+	var groups []HouseDocuments
+
+	index := make(map[string]int)
+	add := func(key string, h *House) {
+		index[key] = len(groups)
+		groups = append(groups, HouseDocuments{House: h})
+	}
+	add("", nil)
+	for _, h := range houses {
+		add(h.Key(), h)
+	}
+
+	for _, doc := range docs {
+		idx, ok := index[doc.Room.House]
+		if !ok {
+			idx = 0
+		}
+		groups[idx].Docs = append(groups[idx].Docs, doc)
+	}
+
+	res := groups[:0]
+	for _, g := range groups {
+		if len(g.Docs) > 0 {
+			res = append(res, g)
+		}
+	}
+	return res
+}
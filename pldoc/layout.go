@@ -0,0 +1,321 @@
+package pldoc
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+const DefaultLayoutName = "default"
+
+// Подпись строки. Строка ищется по тексту в колонке LabelCol без учёта регистра и лишних пробелов.
+// Occurrence - номер вхождения подписи (0 и 1 - первое, -1 - последнее);
+// если указана подпись After, то поиск ведётся в строках после неё.
+type RowLabel struct {
+	Label      string `yaml:"label,omitempty"`
+	LabelCol   int    `yaml:"label_col,omitempty"`
+	Occurrence int    `yaml:"occurrence,omitempty"`
+	After      string `yaml:"after,omitempty"`
+}
+
+// Расположение значения в платёжном документе.
+// Если указана подпись строки (Label), то Row задаёт смещение от найденной строки; иначе Row - номер строки.
+// Если указано регулярное выражение, то значением считается его первая группа.
+type CellRule struct {
+	RowLabel `yaml:",inline"`
+	Row      int    `yaml:"row"`
+	Col      int    `yaml:"col"`
+	Regexp   string `yaml:"regexp,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Колонки строки с взносом на капитальный ремонт
+type CapitalRepairLayout struct {
+	RowLabel      `yaml:",inline"`
+	Rate          int `yaml:"rate"`
+	Charged       int `yaml:"charged"`
+	Recalculation int `yaml:"recalculation"`
+	Total         int `yaml:"total"`
+}
+
+// Колонки таблицы услуг. Таблица начинается после строки с подписью Label
+// и заканчивается строкой итоговой суммы.
+type ServicesLayout struct {
+	RowLabel      `yaml:",inline"`
+	Name          int `yaml:"name"`
+	Price         int `yaml:"price"`
+	Volume        int `yaml:"volume"`
+	Charged       int `yaml:"charged"`
+	Recalculation int `yaml:"recalculation"`
+	Total         int `yaml:"total"`
+	Comment       int `yaml:"comment,omitempty"` // комментарий к строке (0 - колонки нет)
+}
+
+// Профиль расположения полей в платёжном документе
+type Layout struct {
+	Name  string   `yaml:"-"`
+	Files []string `yaml:"files,omitempty"` // шаблоны имён входных файлов, для которых профиль выбирается автоматически
+
+	// Тип помещения для всех документов профиля ("live", "office");
+	// если не задан, то определяется по строке адреса
+	PremisesType string `yaml:"premises_type,omitempty"`
+
+	Period      CellRule `yaml:"period"`
+	Account     CellRule `yaml:"account"`
+	Address     CellRule `yaml:"address"`
+	Area        CellRule `yaml:"area"`
+	BankAccount CellRule `yaml:"bank_account"`
+	BIK         CellRule `yaml:"bik"`
+	Total       CellRule `yaml:"total"`
+
+	// необязательные значения (если не заданы, то берутся из реестра помещений)
+	LivingArea *CellRule `yaml:"living_area,omitempty"`
+	HeatedArea *CellRule `yaml:"heated_area,omitempty"`
+	Residents  *CellRule `yaml:"residents,omitempty"`
+
+	// Долг (больше 0) или переплата (меньше 0) на начало периода; если строки нет, то 0
+	// и предупреждение при разборе документа
+	Balance *CellRule `yaml:"balance,omitempty"`
+	// Число месяца, до которого учтены платежи (0 - последний день расчётного периода)
+	PaymentsDay int `yaml:"payments_day,omitempty"`
+
+	CapitalRepair CapitalRepairLayout `yaml:"capital_repair"`
+	Services      ServicesLayout      `yaml:"services"`
+}
+
+// Набор профилей по именам
+type Layouts map[string]*Layout
+
+// Профиль, соответствующий формату платёжных документов биллинговой программы
+func DefaultLayout() *Layout {
+	return &Layout{
+		Name:        DefaultLayoutName,
+		Period:      CellRule{Row: 0, Col: 0, Regexp: `^\s*Платежный документ \(счёт\) за (.+) г\.$`},
+		Account:     CellRule{Row: 7, Col: 6, Regexp: `^л/с (.+)$`},
+		Address:     CellRule{Row: 8, Col: 0},
+		Area:        CellRule{Row: 9, Col: 0, Regexp: `Пл\.:\s+(\S+) кв\.м\.`},
+		BankAccount: CellRule{Row: 12, Col: 0, Regexp: `р/счет (\S+) `},
+		BIK:         CellRule{Row: 12, Col: 0, Regexp: `БИК (.*)$`},
+		Total:       CellRule{RowLabel: RowLabel{Label: "Итого", After: "Услуга"}, Col: 10},
+		Balance:     &CellRule{RowLabel: RowLabel{Label: "Долг/переплата"}, Col: 10}, // подпись не сверена с выгрузкой
+		CapitalRepair: CapitalRepairLayout{
+			RowLabel:      RowLabel{Label: "Отчисления на капитальный ремонт"},
+			Rate:          4,
+			Charged:       6,
+			Recalculation: 7,
+			Total:         8,
+		},
+		Services: ServicesLayout{
+			RowLabel:      RowLabel{Label: "Услуга"},
+			Name:          0,
+			Price:         4,
+			Volume:        5,
+			Charged:       6,
+			Recalculation: 7,
+			Total:         10,
+		},
+	}
+}
+
+// Набор профилей, содержащий только профиль по умолчанию
+func DefaultLayouts() Layouts {
+	def := DefaultLayout()
+	def.compile()
+	return Layouts{def.Name: def}
+}
+
+// Читает профили из файла (YAML или JSON). Незаданные в профиле поля берутся
+// из профиля по умолчанию, профиль "default" можно переопределить.
+func LoadLayouts(fileName string) (Layouts, error) {
+	var raw map[string]interface{}
+
+	if err := loadConfigFile(fileName, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+
+	layouts := DefaultLayouts()
+	for name, node := range raw {
+		layout := DefaultLayout()
+		layout.resetRules(node)
+		if err := mergeConfigNode(node, layout); err != nil {
+			return nil, fmt.Errorf("%s: profile %s: %v", fileName, name, err)
+		}
+		layout.Name = name
+		if err := layout.compile(); err != nil {
+			return nil, fmt.Errorf("%s: profile %s: %v", fileName, name, err)
+		}
+		layouts[name] = layout
+	}
+	return layouts, nil
+}
+
+// Сбрасывает правила профиля по умолчанию, заданные в узле профиля: правило из профиля
+// заменяет правило по умолчанию целиком (иначе, например, row из профиля считался бы
+// смещением от подписи строки из правила по умолчанию)
+func (layout *Layout) resetRules(node interface{}) {
+	m, ok := node.(map[interface{}]interface{})
+	if !ok {
+		return
+	}
+	for key, rule := range map[string]*CellRule{
+		"period":       &layout.Period,
+		"account":      &layout.Account,
+		"address":      &layout.Address,
+		"area":         &layout.Area,
+		"bank_account": &layout.BankAccount,
+		"bik":          &layout.BIK,
+		"total":        &layout.Total,
+	} {
+		if _, ok := m[key]; ok {
+			*rule = CellRule{}
+		}
+	}
+	for key, rule := range map[string]**CellRule{
+		"living_area": &layout.LivingArea,
+		"heated_area": &layout.HeatedArea,
+		"residents":   &layout.Residents,
+		"balance":     &layout.Balance,
+	} {
+		if _, ok := m[key]; ok {
+			*rule = nil
+		}
+	}
+}
+
+// Выбирает профиль для входного файла: профиль с именем name, если оно задано,
+// иначе первый (по имени) профиль, шаблон которого подходит к файлу, иначе профиль по умолчанию
+func (layouts Layouts) Select(fileName string, name string) (*Layout, error) {
+	if name != "" {
+		layout, ok := layouts[name]
+		if !ok {
+			return nil, fmt.Errorf("layout profile %s not found", name)
+		}
+		return layout, nil
+	}
+
+	names := make([]string, 0, len(layouts))
+	for n := range layouts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		for _, pattern := range layouts[n].Files {
+			if ok, _ := filepath.Match(pattern, fileName); ok {
+				return layouts[n], nil
+			}
+			if ok, _ := filepath.Match(pattern, filepath.Base(fileName)); ok {
+				return layouts[n], nil
+			}
+		}
+	}
+
+	layout, ok := layouts[DefaultLayoutName]
+	if !ok {
+		return nil, fmt.Errorf("layout profile %s not found", DefaultLayoutName)
+	}
+	return layout, nil
+}
+
+func (layout *Layout) compile() error {
+	if layout.PremisesType != "" {
+		if _, ok := ParseRoomType(layout.PremisesType); !ok {
+			return fmt.Errorf("invalid premises type '%s'", layout.PremisesType)
+		}
+	}
+	if layout.PaymentsDay < 0 || layout.PaymentsDay > 31 {
+		return fmt.Errorf("invalid payments day %d", layout.PaymentsDay)
+	}
+	for _, rule := range []*CellRule{&layout.Period, &layout.Account, &layout.Address, &layout.Area,
+		&layout.BankAccount, &layout.BIK, &layout.Total, layout.LivingArea, layout.HeatedArea, layout.Residents,
+		layout.Balance} {
+		if rule == nil {
+			continue
+		}
+		if err := rule.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rule *CellRule) compile() error {
+	rule.re = nil
+	if rule.Regexp == "" {
+		return nil
+	}
+	re, err := regexp.Compile(rule.Regexp)
+	if err != nil {
+		return err
+	}
+	rule.re = re
+	return nil
+}
+
+// Номер строки с подписью (-1, если не найдена)
+func (l *RowLabel) find(rows *rowDesc) int {
+	q := RowQuery{Label: l.Label, Col: l.LabelCol, Occurrence: l.Occurrence}
+	if l.After != "" {
+		after := rows.Find(RowQuery{Label: l.After, Col: l.LabelCol})
+		if after < 0 {
+			return -1
+		}
+		q.From = after + 1
+	}
+	return rows.Find(q)
+}
+
+// Описание подписи для сообщений об ошибках
+func (l *RowLabel) String() string {
+	if l.After != "" {
+		return fmt.Sprintf("'%s' after '%s'", l.Label, l.After)
+	}
+	return fmt.Sprintf("'%s'", l.Label)
+}
+
+// Номер строки, на которую указывает правило (-1, если подпись строки не найдена)
+func (rule *CellRule) rowIndex(rows *rowDesc) int {
+	if rule.Label == "" {
+		return rule.Row
+	}
+	idx := rule.find(rows)
+	if idx < 0 {
+		return -1
+	}
+	return idx + rule.Row
+}
+
+// Извлекает значение по правилу. Возвращает значение, исходный текст ячейки и признак успеха.
+func (rule *CellRule) value(sheet Sheet, rows *rowDesc) (resStr string, cellStr string, found bool) {
+	row := rule.rowIndex(rows)
+	if row < 0 {
+		return
+	}
+	cellStr = cellString(sheet, row, rule.Col)
+
+	if rule.re == nil {
+		return cellStr, cellStr, true
+	}
+	m := rule.re.FindStringSubmatch(cellStr)
+	if m == nil {
+		return
+	}
+	if len(m) > 1 {
+		resStr = m[1]
+	} else {
+		resStr = m[0]
+	}
+	found = true
+	return
+}
+
+// Ошибка в ячейке, на которую указывает правило
+func (rule *CellRule) error(rows *rowDesc, cellStr string, err error) *ParseError {
+	row := rule.rowIndex(rows)
+	if row < 0 {
+		// не найдена строка с подписью
+		return docError(fmt.Errorf("%w: row %s not found", err, rule.RowLabel.String()))
+	}
+	return cellError(row, rule.Col, cellStr, err)
+}
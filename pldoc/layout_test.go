@@ -0,0 +1,86 @@
+package pldoc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Записывает файл настроек во временный каталог теста
+func writeConfig(t *testing.T, name string, data string) string {
+	t.Helper()
+	fileName := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fileName, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestLayoutsSelect(t *testing.T) {
+	layouts, err := LoadLayouts(writeConfig(t, "layouts.yaml", `
+shifted:
+  files: ['In/shifted/*.xls']
+offices:
+  files: ['*_office.xls']
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		fileName, profile string
+		want              string
+	}{
+		{"In/shifted/1.xls", "", "shifted"},
+		{"In/shifted/sub/1.xls", "", DefaultLayoutName},
+		{"In/12_office.xls", "", "offices"},
+		{"12_office.xls", "", "offices"},
+		{"In/12.xls", "", DefaultLayoutName},
+		{"In/shifted/1.xls", "offices", "offices"},
+		{"In/12.xls", DefaultLayoutName, DefaultLayoutName},
+	} {
+		layout, err := layouts.Select(tt.fileName, tt.profile)
+		if err != nil {
+			t.Errorf("Select(%q, %q): unexpected error %v", tt.fileName, tt.profile, err)
+			continue
+		}
+		if layout.Name != tt.want {
+			t.Errorf("Select(%q, %q) = %s, want %s", tt.fileName, tt.profile, layout.Name, tt.want)
+		}
+	}
+	if _, err := layouts.Select("In/12.xls", "unknown"); err == nil {
+		t.Errorf("Select with unknown profile: want error")
+	}
+}
+
+func TestLoadLayoutsReplacesRule(t *testing.T) {
+	layouts, err := LoadLayouts(writeConfig(t, "layouts.yaml", `
+fixed:
+  total: {row: 30, col: 9}
+  period: {label: 'Период', col: 1}
+  balance: {row: 5, col: 3}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout := layouts["fixed"]
+	want := CellRule{Row: 30, Col: 9}
+	if layout.Total.RowLabel != want.RowLabel || layout.Total.Row != want.Row || layout.Total.Col != want.Col ||
+		layout.Total.Regexp != "" {
+		t.Errorf("total = %+v, want %+v (without default label)", layout.Total, want)
+	}
+	if layout.Period.Label != "Период" || layout.Period.Regexp != "" {
+		t.Errorf("period = %+v, want label without default regexp", layout.Period)
+	}
+	if layout.Balance == nil || layout.Balance.Label != "" || layout.Balance.Row != 5 {
+		t.Errorf("balance = %+v, want row 5 without default label", layout.Balance)
+	}
+
+	// правила, не указанные в профиле, берутся из профиля по умолчанию
+	def := DefaultLayout()
+	if layout.Account.Regexp != def.Account.Regexp || layout.Account.Row != def.Account.Row {
+		t.Errorf("account = %+v, want default %+v", layout.Account, def.Account)
+	}
+	if layout.Services != def.Services {
+		t.Errorf("services = %+v, want default %+v", layout.Services, def.Services)
+	}
+}
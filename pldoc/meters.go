@@ -0,0 +1,246 @@
+package pldoc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+// Способы определения объемов КУ
+const (
+	MethodMeter = "Прибор учета"
+	MethodNorm  = "Норматив"
+)
+
+// Заголовки колонок файла показаний (сравниваются без учёта регистра)
+const (
+	readingsHouseHeader   = "дом"
+	readingsRoomHeader    = "помещение"
+	readingsServiceHeader = "услуга"
+	readingsValueHeader   = "показания"
+)
+
+// Показание прибора учета по помещению и услуге
+type readingKey struct {
+	room    RoomID
+	service string // нормализованное название услуги в таблице соответствия услуг
+}
+
+// Показания приборов учета: индивидуальных (по помещениям) и коллективных (по дому)
+type MeterReadings struct {
+	individual map[readingKey]float64
+	collective map[string]float64
+}
+
+// Нормативы потребления по названиям услуг в таблице соответствия услуг
+type Norms map[string]float64
+
+// Показания и нормативы, по которым заполняются колонки нормативов и показаний в шаблоне
+type Meters struct {
+	Readings *MeterReadings
+	Norms    Norms
+}
+
+// Читает показания приборов учета из CSV (';') или книги Excel (.xlsx, первый лист).
+// Колонки определяются по заголовкам "Помещение", "Услуга" (название из таблицы соответствия услуг),
+// "Показания" и необязательному "Дом"
+// (код дома, ГУИД ФИАС или адрес, см. Houses); строки с пустым помещением - показания
+// коллективных (общедомовых) приборов учета. Помещение - номер квартиры или текст,
+// распознаваемый правилами rules.
+func LoadMeterReadings(fileName string, rules PremisesRules, houses Houses) (*MeterReadings, error) {
+	rows, err := readTable(fileName)
+	if err != nil {
+		return nil, &ParseError{File: fileName, Row: -1, Col: -1, Err: err}
+	}
+
+	readings := &MeterReadings{
+		individual: make(map[readingKey]float64),
+		collective: make(map[string]float64),
+	}
+	colHouse, colRoom, colService, colValue := -1, -1, -1, -1
+	for i, row := range rows {
+		if colService < 0 {
+			// ищем строку заголовков
+			for j, v := range row {
+				switch normalizeLabel(v) {
+				case readingsHouseHeader:
+					colHouse = j
+				case readingsRoomHeader:
+					colRoom = j
+				case readingsServiceHeader:
+					colService = j
+				case readingsValueHeader:
+					colValue = j
+				}
+			}
+			if colService >= 0 && (colRoom < 0 || colValue < 0) {
+				return nil, &ParseError{File: fileName, Row: i, Col: -1,
+					Err: fmt.Errorf("columns '%s', '%s', '%s' expected", readingsRoomHeader, readingsServiceHeader, readingsValueHeader)}
+			}
+			continue
+		}
+
+		service := normalizeLabel(tableCell(row, colService))
+		valueStr := tableCell(row, colValue)
+		if service == "" || strings.TrimSpace(valueStr) == "" {
+			continue
+		}
+		value, err := ParseNumber(valueStr)
+		if err != nil {
+			return nil, &ParseError{File: fileName, Row: i, Col: colValue, Text: valueStr, Err: ErrInvalidNumber}
+		}
+
+		houseKey := ""
+		if houseStr := strings.TrimSpace(tableCell(row, colHouse)); houseStr != "" {
+			house, ok := houses.Find(houseStr)
+			if !ok {
+				return nil, &ParseError{File: fileName, Row: i, Col: colHouse, Text: houseStr, Err: ErrHouseNotFound}
+			}
+			houseKey = house.Key()
+		}
+
+		roomStr := strings.TrimSpace(tableCell(row, colRoom))
+		if roomStr == "" {
+			readings.collective[collectiveKey(houseKey, service)] = value
+			continue
+		}
+		room, ok := parseReadingsRoom(roomStr, rules)
+		if !ok {
+			return nil, &ParseError{File: fileName, Row: i, Col: colRoom, Text: roomStr, Err: ErrMissingRoomID}
+		}
+		room.House = houseKey
+		readings.individual[readingKey{room: room, service: service}] = value
+	}
+	if colService < 0 {
+		return nil, &ParseError{File: fileName, Row: -1, Col: -1,
+			Err: fmt.Errorf("header row with column '%s' not found", readingsServiceHeader)}
+	}
+	return readings, nil
+}
+
+// Помещение в файле показаний: номер квартиры или текст для правил распознавания помещений
+func parseReadingsRoom(s string, rules PremisesRules) (RoomID, bool) {
+	if room, ok := ParsePremisesNumber(s); ok {
+		return room, true
+	}
+	return rules.Match(s)
+}
+
+// Читает таблицу из CSV (';') или с первого листа книги Excel
+func readTable(fileName string) ([][]string, error) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if ext == ".xlsx" || ext == ".xlsm" {
+		xlFile, err := xlsx.OpenFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		if len(xlFile.Sheets) == 0 {
+			return nil, ErrSheetNotFound
+		}
+		var rows [][]string
+		for _, xlRow := range xlFile.Sheets[0].Rows {
+			var row []string
+			if xlRow != nil {
+				for _, cell := range xlRow.Cells {
+					row = append(row, cell.String())
+				}
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comma = ';'
+	r.FieldsPerRecord = -1
+	return r.ReadAll()
+}
+
+func tableCell(row []string, col int) string {
+	if col < 0 || col >= len(row) {
+		return ""
+	}
+	return row[col]
+}
+
+func collectiveKey(house string, service string) string {
+	return house + "\x00" + service
+}
+
+// Показание прибора учета для строки услуги: индивидуального для индивидуального потребления,
+// коллективного - для потребления при содержании общего имущества.
+// Показания без указания дома подходят к помещениям любого дома.
+func (r *MeterReadings) Find(room RoomID, line *ServiceLine) (float64, bool) {
+	if r == nil {
+		return 0, false
+	}
+	service := normalizeLabel(line.ServiceName)
+	if line.Individual() {
+		if v, ok := r.individual[readingKey{room: room, service: service}]; ok {
+			return v, ok
+		}
+		room.House = ""
+		v, ok := r.individual[readingKey{room: room, service: service}]
+		return v, ok
+	}
+	if v, ok := r.collective[collectiveKey(room.House, service)]; ok {
+		return v, ok
+	}
+	v, ok := r.collective[collectiveKey("", service)]
+	return v, ok
+}
+
+// Читает нормативы потребления из файла (YAML или JSON): название услуги - норматив
+func LoadNorms(fileName string) (Norms, error) {
+	var raw map[string]float64
+
+	if err := loadConfigFile(fileName, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	norms := make(Norms, len(raw))
+	for name, v := range raw {
+		norms[normalizeLabel(name)] = v
+	}
+	return norms, nil
+}
+
+// Норматив потребления для строки услуги
+func (n Norms) Find(line *ServiceLine) (float64, bool) {
+	v, ok := n[normalizeLabel(line.ServiceName)]
+	return v, ok
+}
+
+// Заполняет в строках услуг документа показания и нормативы и выбирает способ определения
+// объемов: по прибору учета, если есть показание, по нормативу, если есть только норматив;
+// иначе остаётся способ из таблицы соответствия услуг
+func (m *Meters) Apply(doc *PaymentDocument) {
+	for i := range doc.Services {
+		line := &doc.Services[i]
+		if line.Group == GroupMaintenance || line.Additional() {
+			continue
+		}
+		norm, hasNorm := m.Norms.Find(line)
+		reading, hasReading := m.Readings.Find(doc.Room, line)
+		if hasNorm {
+			line.Norm = norm
+		}
+		switch {
+		case hasReading:
+			line.Reading = reading
+			line.HasReading = true
+			line.Method = MethodMeter
+		case hasNorm:
+			line.Method = MethodNorm
+		}
+	}
+}
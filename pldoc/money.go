@@ -0,0 +1,196 @@
+package pldoc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Денежная сумма в копейках
+type Money int64
+
+// Тариф (размер платы на единицу объёма или на кв.м) в миллионных долях рубля:
+// тарифы бывают с тремя и более знаками после запятой ("4,185"), а в копейках они бы округлялись
+type Price int64
+
+// Количество знаков после запятой в Price
+const priceDigits = 6
+
+// Разбирает сумму в рублях: "1234.56", "1 234,56", "-12,5". Пустая строка - ноль.
+// Более двух знаков после запятой округляются до копеек (половина - от нуля).
+func ParseMoney(s string) (Money, error) {
+	v, err := parseFixed(s, 2)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount '%s'", s)
+	}
+	return Money(v), nil
+}
+
+// Разбирает тариф в рублях в тех же форматах, что и ParseMoney ("4,185").
+// Более шести знаков после запятой (погрешность числа в ячейке) округляются.
+func ParsePrice(s string) (Price, error) {
+	v, err := parseFixed(s, priceDigits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid price '%s'", s)
+	}
+	return Price(v), nil
+}
+
+// Разбирает число с фиксированной точкой: digits знаков после запятой,
+// следующие знаки округляются (половина - от нуля)
+func parseFixed(s string, digits int) (int64, error) {
+	str := normalizeNumber(s)
+	if str == "" {
+		return 0, nil
+	}
+
+	negative := false
+	switch str[0] {
+	case '-':
+		negative = true
+		str = str[1:]
+	case '+':
+		str = str[1:]
+	}
+
+	intPart, fracPart := str, ""
+	if p := strings.IndexByte(str, '.'); p >= 0 {
+		intPart, fracPart = str[:p], str[p+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return 0, ErrInvalidNumber
+	}
+	for _, c := range intPart + fracPart {
+		if c < '0' || c > '9' {
+			return 0, ErrInvalidNumber
+		}
+	}
+
+	var v int64
+	if intPart != "" {
+		var err error
+		v, err = strconv.ParseInt(intPart, 10, 64)
+		if err != nil {
+			return 0, ErrInvalidNumber
+		}
+	}
+
+	// дробная часть и округление по следующему знаку
+	for i := 0; i < digits; i++ {
+		v *= 10
+		if i < len(fracPart) {
+			v += int64(fracPart[i] - '0')
+		}
+	}
+	if len(fracPart) > digits && fracPart[digits] >= '5' {
+		v++
+	}
+
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// Разбирает число (объём, площадь) в любом из форматов, допустимых для ParseMoney
+func ParseNumber(s string) (float64, error) {
+	str := normalizeNumber(s)
+	if str == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number '%s'", s)
+	}
+	return v, nil
+}
+
+// Удаляет разделители разрядов (пробелы, неразрывные пробелы) и заменяет десятичную запятую точкой
+func normalizeNumber(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\u2009', '\t':
+			return -1
+		case ',':
+			return '.'
+		}
+		return r
+	}, s)
+	return s
+}
+
+// Сумма в рублях с двумя знаками после точки
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// Сумма в рублях для записи в числовую ячейку
+func (m Money) Float() float64 {
+	return float64(m) / 100
+}
+
+// Произведение суммы на количество, округлённое до копеек
+func (m Money) Mul(q float64) Money {
+	v := float64(m) * q
+	if v < 0 {
+		return Money(v - 0.5)
+	}
+	return Money(v + 0.5)
+}
+
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// Set реализует flag.Value
+func (m *Money) Set(s string) error {
+	v, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// MarshalJSON выводит сумму числом в рублях
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Тариф в рублях: не менее двух знаков после точки, незначащие нули отбрасываются ("4.185", "30.00")
+func (p Price) String() string {
+	sign := ""
+	v := int64(p)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	frac := strings.TrimRight(fmt.Sprintf("%06d", v%1000000), "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	return fmt.Sprintf("%s%d.%s", sign, v/1000000, frac)
+}
+
+// Тариф в рублях для записи в числовую ячейку
+func (p Price) Float() float64 {
+	return float64(p) / 1000000
+}
+
+// Стоимость объёма по тарифу, округлённая до копеек
+func (p Price) Mul(q float64) Money {
+	v := float64(p) * q / 10000
+	if v < 0 {
+		return Money(v - 0.5)
+	}
+	return Money(v + 0.5)
+}
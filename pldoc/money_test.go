@@ -0,0 +1,121 @@
+package pldoc
+
+import "testing"
+
+func TestParseMoney(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want Money
+	}{
+		{"", 0},
+		{"0", 0},
+		{"1234.56", 123456},
+		{"1 234,56", 123456},
+		{"1 234,56", 123456},
+		{"-12,5", -1250},
+		{"+7", 700},
+		{",5", 50},
+		{"12.", 1200},
+		{"0.994", 99},
+		{"0.995", 100},
+		{"-0.005", -1},
+		{"-0.004", 0},
+		{"99.999", 10000},
+	} {
+		got, err := ParseMoney(tt.s)
+		if err != nil {
+			t.Errorf("ParseMoney(%q): unexpected error %v", tt.s, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseMoneyInvalid(t *testing.T) {
+	for _, s := range []string{"1.234,56", "12a", "-", ".", "1-2", "1e3"} {
+		if got, err := ParseMoney(s); err == nil {
+			t.Errorf("ParseMoney(%q) = %d, want error", s, got)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	for _, tt := range []struct {
+		m    Money
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{123456, "1234.56"},
+	} {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMul(t *testing.T) {
+	for _, tt := range []struct {
+		m    Money
+		q    float64
+		want Money
+	}{
+		{1000, 0.5, 500},
+		{1001, 0.5, 501},
+		{-1001, 0.5, -501},
+		{100, 1.0 / 3, 33},
+	} {
+		if got := tt.m.Mul(tt.q); got != tt.want {
+			t.Errorf("Money(%d).Mul(%v) = %d, want %d", tt.m, tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want Price
+		str  string
+	}{
+		{"", 0, "0.00"},
+		{"30", 30000000, "30.00"},
+		{"4,185", 4185000, "4.185"},
+		{"1 234,5678", 1234567800, "1234.5678"},
+		{"0.0000015", 2, "0.000002"},
+		{"4.1850000000000005", 4185000, "4.185"},
+		{"-2.5", -2500000, "-2.50"},
+	} {
+		got, err := ParsePrice(tt.s)
+		if err != nil {
+			t.Errorf("ParsePrice(%q): unexpected error %v", tt.s, err)
+			continue
+		}
+		if got != tt.want || got.String() != tt.str {
+			t.Errorf("ParsePrice(%q) = %d (%s), want %d (%s)", tt.s, got, got, tt.want, tt.str)
+		}
+	}
+	if got, err := ParsePrice("4,18a"); err == nil {
+		t.Errorf("ParsePrice(\"4,18a\") = %d, want error", got)
+	}
+}
+
+func TestPriceMaintenanceSum(t *testing.T) {
+	// тарифы с тремя знаками складываются без округления до копеек
+	var sum Price
+	for _, s := range []string{"4,185", "1,015", "20,3"} {
+		p, err := ParsePrice(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += p
+	}
+	if got := sum.String(); got != "25.50" {
+		t.Errorf("sum = %s, want 25.50", got)
+	}
+	if got := sum.Mul(45.5); got != 116025 {
+		t.Errorf("sum * 45.5 = %s, want 1160.25", got)
+	}
+}
@@ -0,0 +1,399 @@
+package pldoc
+
+import (
+	"fmt"
+	"math"
+)
+
+// Разбор платёжных документов биллинговой программы
+type Parser struct {
+	Layouts  Layouts       // профили расположения полей
+	Profile  string        // профиль расположения полей (пустой - выбор по имени файла)
+	Services *ServiceMap   // таблица соответствия услуг
+	Premises PremisesRules // правила распознавания помещений по строке адреса
+	Houses   Houses        // дома (если заданы, то дом определяется по строке адреса)
+	Period   *Period       // расчётный период для всех документов (если задан, то не ищется в документе)
+
+	Recalculations RecalculationRules // правила определения оснований перерасчётов
+}
+
+// Парсер с профилем расположения полей и таблицей услуг по умолчанию
+func NewParser() *Parser {
+	return &Parser{
+		Layouts:  DefaultLayouts(),
+		Services: DefaultServiceMap(),
+		Premises: DefaultPremisesRules(),
+	}
+}
+
+// Платёжный документ во входном файле: лист и первая строка блока документа на листе
+type DocumentBlock struct {
+	Sheet string
+	Row   int
+	Doc   *PaymentDocument // разобранный документ (nil при ошибке)
+	Err   error            // ошибка разбора документа (*ParseError)
+
+	Warnings []Problem // предупреждения разбора документа (например, не найдена необязательная строка)
+}
+
+// Разбирает платёжные документы, выгруженные из биллинговой программы (.xls, .xlsx, .xlsm).
+// Документы ищутся на всех листах книги, несколько документов на одном листе разделяются
+// по строке заголовка с расчётным периодом. Лист с данными, на котором нет заголовка,
+// разбирается целиком, чтобы сообщить, чего в нём нет (пустые листы и листы из одной строки
+// пропускаются). Ошибка разбора отдельного документа возвращается в его блоке,
+// а ошибка открытия файла - как *ParseError с именем файла.
+func (p *Parser) ParseFile(excelPD string) ([]DocumentBlock, error) {
+	layout, err := p.Layouts.Select(excelPD, p.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets, err := OpenSheets(excelPD)
+	if err != nil {
+		return nil, &ParseError{File: excelPD, Row: -1, Col: -1, Err: fmt.Errorf("open input file: %v", err)}
+	}
+	if len(sheets) == 0 {
+		return nil, &ParseError{File: excelPD, Row: -1, Col: -1, Err: ErrSheetNotFound}
+	}
+
+	var blocks []DocumentBlock
+	for _, xlSheet := range sheets {
+		starts := splitDocuments(xlSheet, layout)
+		if len(starts) == 0 && xlSheet.MaxRow() > 0 {
+			blocks = append(blocks, p.parseBlock(excelPD, &sheetBlock{Sheet: xlSheet, from: 0, to: xlSheet.MaxRow()}, layout))
+			continue
+		}
+		for i, start := range starts {
+			end := xlSheet.MaxRow()
+			if i+1 < len(starts) {
+				end = starts[i+1] - 1
+			}
+			blocks = append(blocks, p.parseBlock(excelPD, &sheetBlock{Sheet: xlSheet, from: start, to: end}, layout))
+		}
+	}
+	if len(blocks) == 0 {
+		// в книге нет данных: разбираем первый лист, чтобы сообщить, чего в нём нет
+		blocks = append(blocks, p.parseBlock(excelPD, &sheetBlock{Sheet: sheets[0], from: 0, to: sheets[0].MaxRow()}, layout))
+	}
+	return blocks, nil
+}
+
+// Предупреждение разбора документа: поле шаблона и место в документе
+type parseWarning struct {
+	field string
+	err   *ParseError
+}
+
+// Разбирает блок листа с одним платёжным документом
+func (p *Parser) parseBlock(excelPD string, xlSheetPD *sheetBlock, layout *Layout) DocumentBlock {
+	block := DocumentBlock{Sheet: xlSheetPD.Name(), Row: xlSheetPD.from}
+
+	doc, err := p.ParseSheet(xlSheetPD, layout)
+	if err != nil {
+		errParse, ok := err.(*ParseError)
+		if !ok {
+			errParse = docError(err)
+		}
+		xlSheetPD.locate(errParse)
+		errParse.File = excelPD
+		block.Err = errParse
+		return block
+	}
+	doc.SourceFile = excelPD
+	for _, w := range doc.warnings {
+		xlSheetPD.locate(w.err)
+		problem := doc.warning(w.field, w.err.Error())
+		problem.Err = w.err
+		block.Warnings = append(block.Warnings, problem)
+	}
+	doc.warnings = nil
+	block.Doc = doc
+	return block
+}
+
+// Номера первых строк платёжных документов на листе: документ начинается
+// со строки заголовка, в которой находится расчётный период (правило period профиля)
+func splitDocuments(sheet Sheet, layout *Layout) []int {
+	var starts []int
+
+	rule := &layout.Period
+	if rule.Label != "" || rule.re == nil {
+		// по правилу нельзя найти заголовок документа, считаем, что на листе один документ
+		return []int{0}
+	}
+	for i := 0; i <= sheet.MaxRow(); i++ {
+		if !rule.re.MatchString(cellString(sheet, i, rule.Col)) {
+			continue
+		}
+		start := i - rule.Row
+		if start < 0 {
+			start = 0
+		}
+		if len(starts) > 0 && start <= starts[len(starts)-1] {
+			continue
+		}
+		starts = append(starts, start)
+	}
+	return starts
+}
+
+// Разбирает лист с платёжным документом согласно профилю расположения полей.
+// Ошибки возвращаются как *ParseError с местом в документе.
+// Если в документе есть неизвестные услуги, то ошибка содержит *UnknownServicesError со списком всех таких услуг.
+func (p *Parser) ParseSheet(xlSheetPD Sheet, layout *Layout) (*PaymentDocument, error) {
+	var (
+		valStr          string
+		doc             PaymentDocument
+		unknownServices []string
+		err             error
+	)
+
+	mapRowDescInSheet := newRowDesc(xlSheetPD)
+
+	// ищем период оплаты
+	if p.Period != nil {
+		doc.Period = *p.Period
+	} else {
+		periodStr, valStr, periodExists := layout.Period.value(xlSheetPD, mapRowDescInSheet)
+		if !periodExists {
+			return nil, layout.Period.error(mapRowDescInSheet, valStr, ErrPeriodNotFound)
+		}
+		period, err := ParsePeriod(periodStr)
+		if err != nil {
+			return nil, layout.Period.error(mapRowDescInSheet, valStr, fmt.Errorf("%w: %v", ErrInvalidPeriod, err))
+		}
+		doc.Period = period
+	}
+
+	// ищем номер лицевого счёта
+	accountStr, valStr, accountExists := layout.Account.value(xlSheetPD, mapRowDescInSheet)
+	if !accountExists {
+		return nil, layout.Account.error(mapRowDescInSheet, valStr, ErrAccountNotFound)
+	}
+	doc.Account = accountStr
+
+	// ищем номер квартиры (офиса)
+	addressStr, valStr, addressExists := layout.Address.value(xlSheetPD, mapRowDescInSheet)
+	if !addressExists {
+		return nil, layout.Address.error(mapRowDescInSheet, valStr, ErrAddressNotFound)
+	}
+	room, roomExists := p.Premises.Match(addressStr)
+	if !roomExists {
+		return nil, layout.Address.error(mapRowDescInSheet, addressStr, ErrMissingRoomID)
+	}
+	if layout.PremisesType != "" {
+		// тип проверен при загрузке профиля
+		room.Type, _ = ParseRoomType(layout.PremisesType)
+	}
+	if len(p.Houses) > 0 {
+		house, ok := p.Houses.MatchAddress(addressStr)
+		if !ok {
+			return nil, layout.Address.error(mapRowDescInSheet, addressStr, ErrHouseNotFound)
+		}
+		room.House = house.Key()
+	}
+	doc.Room = room
+
+	// ищем площади и количество проживающих (если их нет в документе, то они берутся из реестра помещений)
+	if doc.Area, err = optionalNumber(&layout.Area, xlSheetPD, mapRowDescInSheet); err != nil {
+		return nil, err
+	}
+	if doc.LivingArea, err = optionalNumber(layout.LivingArea, xlSheetPD, mapRowDescInSheet); err != nil {
+		return nil, err
+	}
+	if doc.HeatedArea, err = optionalNumber(layout.HeatedArea, xlSheetPD, mapRowDescInSheet); err != nil {
+		return nil, err
+	}
+	residents, err := optionalNumber(layout.Residents, xlSheetPD, mapRowDescInSheet)
+	if err != nil {
+		return nil, err
+	}
+	doc.Residents = int(math.Round(residents))
+
+	// долг или переплата на начало периода
+	if layout.Balance != nil {
+		balanceStr, valStr, balanceExists := layout.Balance.value(xlSheetPD, mapRowDescInSheet)
+		if balanceExists {
+			if doc.Balance, err = ParseMoney(balanceStr); err != nil {
+				return nil, layout.Balance.error(mapRowDescInSheet, valStr, ErrInvalidNumber)
+			}
+		} else {
+			// долг считается равным 0, но строка могла не найтись из-за другой подписи
+			doc.warnings = append(doc.warnings, parseWarning{field: "Задолженность за предыдущие периоды",
+				err: layout.Balance.error(mapRowDescInSheet, valStr, ErrBalanceNotFound)})
+		}
+	}
+	// платежи учтены до последнего дня расчётного периода, если в профиле не задано другое число
+	doc.PaymentsDay = doc.Period.Days()
+	if layout.PaymentsDay > 0 && layout.PaymentsDay < doc.PaymentsDay {
+		doc.PaymentsDay = layout.PaymentsDay
+	}
+
+	// БИК и расчётный счёт
+	bankAccountStr, valStr, bankAccountExists := layout.BankAccount.value(xlSheetPD, mapRowDescInSheet)
+	if !bankAccountExists {
+		return nil, layout.BankAccount.error(mapRowDescInSheet, valStr, ErrBankAccountNotFound)
+	}
+	doc.BankAccount = bankAccountStr
+	bikStr, valStr, bikExists := layout.BIK.value(xlSheetPD, mapRowDescInSheet)
+	if !bikExists {
+		return nil, layout.BIK.error(mapRowDescInSheet, valStr, ErrBIKNotFound)
+	}
+	doc.BIK = bikStr
+
+	// ищем сведения о кап. ремонте
+	rowVal := layout.CapitalRepair.find(mapRowDescInSheet)
+	if rowVal < 0 {
+		return nil, docError(fmt.Errorf("%w: row %s not found", ErrCapitalRepairNotFound, layout.CapitalRepair.RowLabel.String()))
+	}
+	if doc.CapitalRepair.Rate, err = priceCell(xlSheetPD, rowVal, layout.CapitalRepair.Rate); err != nil {
+		return nil, err
+	}
+	if doc.CapitalRepair.Charged, err = moneyCell(xlSheetPD, rowVal, layout.CapitalRepair.Charged); err != nil {
+		return nil, err
+	}
+	if doc.CapitalRepair.Recalculation, err = moneyCell(xlSheetPD, rowVal, layout.CapitalRepair.Recalculation); err != nil {
+		return nil, err
+	}
+	doc.CapitalRepair.HasRecalculation = len(cellString(xlSheetPD, rowVal, layout.CapitalRepair.Recalculation)) > 0
+	if doc.CapitalRepair.Total, err = moneyCell(xlSheetPD, rowVal, layout.CapitalRepair.Total); err != nil {
+		return nil, err
+	}
+
+	// ищем итоговую сумму по платёжному документу
+	rowItogoVal := layout.Total.rowIndex(mapRowDescInSheet)
+	totalDocSumStr, valStr, totalDocSumExists := layout.Total.value(xlSheetPD, mapRowDescInSheet)
+	if !totalDocSumExists {
+		return nil, layout.Total.error(mapRowDescInSheet, valStr, ErrTotalNotFound)
+	}
+	if doc.Total, err = ParseMoney(totalDocSumStr); err != nil {
+		return nil, layout.Total.error(mapRowDescInSheet, valStr, ErrInvalidNumber)
+	}
+
+	// получаем список услуг
+	rowBeginServicesVal := layout.Services.find(mapRowDescInSheet)
+	if rowBeginServicesVal < 0 {
+		return nil, docError(fmt.Errorf("%w: row %s not found", ErrServicesNotFound, layout.Services.RowLabel.String()))
+	}
+
+	// для каждой услуги формируем её описание
+	for i := rowBeginServicesVal + 1; i < rowItogoVal; i++ {
+		// Тип услуги (версия из ПД)
+		serviceStr := cellString(xlSheetPD, i, layout.Services.Name)
+		if serviceStr == "" {
+			continue
+		}
+		// Получаем тип услуги (версия ГИС ЖКХ)
+		service, ok := p.Services.Find(serviceStr)
+		if !ok {
+			unknownServices = append(unknownServices, serviceStr)
+			continue
+		}
+		// Объём
+		volumeStr := cellString(xlSheetPD, i, layout.Services.Volume)
+		volumeVal, err := ParseNumber(volumeStr)
+		if err != nil {
+			return nil, numberError(i, layout.Services.Volume, volumeStr)
+		}
+		// Тариф
+		priceVal, err := priceCell(xlSheetPD, i, layout.Services.Price)
+		if err != nil {
+			return nil, err
+		}
+		// Всего начислено
+		totalValueVal, err := moneyCell(xlSheetPD, i, layout.Services.Charged)
+		if err != nil {
+			return nil, err
+		}
+		// Перерасчёт
+		pereraschetVal, err := moneyCell(xlSheetPD, i, layout.Services.Recalculation)
+		if err != nil {
+			return nil, err
+		}
+		// К оплате
+		totalValueCorrVal, err := moneyCell(xlSheetPD, i, layout.Services.Total)
+		if err != nil {
+			return nil, err
+		}
+
+		switch service.Group {
+		case GroupPenalty:
+			// пени выводятся на отдельный лист
+			doc.Penalties = append(doc.Penalties, Penalty{
+				Kind:   service.GisName,
+				Basis:  service.Basis,
+				Amount: totalValueCorrVal,
+			})
+			continue
+		case GroupMaintenance:
+			// текущее содержание необходимо суммировать с коммунальными услугами за ОИ
+			doc.Maintenance.Price += priceVal
+			doc.Maintenance.Total += totalValueCorrVal
+		case GroupCommon:
+			// по услугам за ОИ надо всё суммировать
+			doc.Maintenance.Price += priceVal
+			doc.Maintenance.Total += totalValueVal
+		}
+
+		line := ServiceLine{
+			Name:          serviceStr,
+			ServiceName:   service.Name,
+			GisName:       service.GisName,
+			Unit:          service.Unit,
+			Method:        service.Method,
+			Group:         service.Group,
+			Volume:        volumeVal,
+			Price:         priceVal,
+			Charged:       totalValueVal,
+			Recalculation: pereraschetVal,
+			Total:         totalValueCorrVal,
+		}
+		if layout.Services.Comment > 0 {
+			line.Comment = cellString(xlSheetPD, i, layout.Services.Comment)
+		}
+		line.RecalculationBasis, _ = p.Recalculations.Basis(&line)
+		doc.Services = append(doc.Services, line)
+	}
+
+	if len(unknownServices) > 0 {
+		return nil, docError(&UnknownServicesError{Services: uniqueServiceNames(unknownServices)})
+	}
+
+	return &doc, nil
+}
+
+// Необязательное число по правилу (0, если правило не задано или значение не найдено)
+func optionalNumber(rule *CellRule, sheet Sheet, rows *rowDesc) (float64, error) {
+	if rule == nil {
+		return 0, nil
+	}
+	valNumStr, valStr, found := rule.value(sheet, rows)
+	if !found {
+		return 0, nil
+	}
+	val, err := ParseNumber(valNumStr)
+	if err != nil {
+		return 0, rule.error(rows, valStr, ErrInvalidNumber)
+	}
+	return val, nil
+}
+
+// Денежная сумма из ячейки row, col (пустая ячейка - 0)
+func moneyCell(sheet Sheet, row int, col int) (Money, error) {
+	valStr := cellString(sheet, row, col)
+	val, err := ParseMoney(valStr)
+	if err != nil {
+		return 0, numberError(row, col, valStr)
+	}
+	return val, nil
+}
+
+// Тариф из ячейки row, col (пустая ячейка - 0)
+func priceCell(sheet Sheet, row int, col int) (Price, error) {
+	valStr := cellString(sheet, row, col)
+	val, err := ParsePrice(valStr)
+	if err != nil {
+		return 0, numberError(row, col, valStr)
+	}
+	return val, nil
+}
@@ -0,0 +1,99 @@
+package pldoc
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestParseFileSheetWithoutHeader(t *testing.T) {
+	xlFile := xlsx.NewFile()
+	for _, sheet := range []struct {
+		name string
+		rows [][]string
+	}{
+		{"Документы", [][]string{
+			{"Платежный документ (счёт) за сентябрь 2019 г."},
+			{"Лицевой счёт", "", "", "1001"},
+		}},
+		{"Пустой", nil},
+		{"Итоги", [][]string{
+			{"Сводная ведомость"},
+			{"Итого", "", "", "1000,00"},
+		}},
+	} {
+		xlSheet, err := xlFile.AddSheet(sheet.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range sheet.rows {
+			xlRow := xlSheet.AddRow()
+			for _, v := range row {
+				xlRow.AddCell().SetString(v)
+			}
+		}
+	}
+	fileName := filepath.Join(t.TempDir(), "pd.xlsx")
+	if err := xlFile.Save(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := NewParser().ParseFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2: %+v", len(blocks), blocks)
+	}
+	if blocks[0].Sheet != "Документы" || blocks[1].Sheet != "Итоги" {
+		t.Errorf("blocks on sheets %q, %q, want Документы, Итоги", blocks[0].Sheet, blocks[1].Sheet)
+	}
+	if !errors.Is(blocks[1].Err, ErrPeriodNotFound) {
+		t.Errorf("sheet without header: error %v, want %v", blocks[1].Err, ErrPeriodNotFound)
+	}
+}
+
+// Документ в формате профиля по умолчанию (строки 0-15)
+func testDocumentSheet(balance string) memSheet {
+	sheet := make(memSheet, 16)
+	sheet[0] = []string{"Платежный документ (счёт) за сентябрь 2019 г."}
+	sheet[7] = []string{"", "", "", "", "", "", "л/с 1001"}
+	sheet[8] = []string{"ул. Ленина, д. 1, кв. 12"}
+	sheet[9] = []string{"Пл.: 45,5 кв.м."}
+	sheet[12] = []string{"р/счет 40702810000000000001 БИК 045004001"}
+	sheet[13] = []string{"Услуга"}
+	sheet[14] = []string{"Итого", "", "", "", "", "", "", "", "", "", "455,00"}
+	sheet[15] = []string{"Отчисления на капитальный ремонт", "", "", "", "10,00", "", "455,00", "", "455,00"}
+	if balance != "" {
+		sheet = append(sheet, []string{"Долг/переплата", "", "", "", "", "", "", "", "", "", balance})
+	}
+	return sheet
+}
+
+func TestParseBlockBalance(t *testing.T) {
+	p := NewParser()
+	layout := p.Layouts[DefaultLayoutName]
+
+	sheet := testDocumentSheet("")
+	block := p.parseBlock("pd.xls", &sheetBlock{Sheet: sheet, from: 0, to: sheet.MaxRow()}, layout)
+	if block.Err != nil {
+		t.Fatal(block.Err)
+	}
+	if len(block.Warnings) != 1 || !errors.Is(block.Warnings[0].Err, ErrBalanceNotFound) || !block.Warnings[0].Warning {
+		t.Errorf("warnings = %+v, want balance not found", block.Warnings)
+	}
+
+	sheet = testDocumentSheet("-100,50")
+	block = p.parseBlock("pd.xls", &sheetBlock{Sheet: sheet, from: 0, to: sheet.MaxRow()}, layout)
+	if block.Err != nil {
+		t.Fatal(block.Err)
+	}
+	if len(block.Warnings) != 0 {
+		t.Errorf("unexpected warnings %+v", block.Warnings)
+	}
+	if block.Doc.Balance != -10050 {
+		t.Errorf("balance = %s, want -100.50", block.Doc.Balance)
+	}
+}
@@ -0,0 +1,105 @@
+package pldoc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Расчётный период (месяц и год)
+type Period struct {
+	Year  int
+	Month time.Month
+}
+
+var rePeriodNumeric = regexp.MustCompile(`^(\d{1,2})\.(\d{4})$`)
+
+var monthNames = [...][2]string{
+	{"январь", "января"},
+	{"февраль", "февраля"},
+	{"март", "марта"},
+	{"апрель", "апреля"},
+	{"май", "мая"},
+	{"июнь", "июня"},
+	{"июль", "июля"},
+	{"август", "августа"},
+	{"сентябрь", "сентября"},
+	{"октябрь", "октября"},
+	{"ноябрь", "ноября"},
+	{"декабрь", "декабря"},
+}
+
+// Преобразует название месяца (на русском, в именительном или родительном падеже,
+// в любом регистре) в номер месяца. Возвращает -1, если месяц не распознан.
+func MonthNameToInt(month string) int {
+	month = strings.ToLower(strings.TrimSpace(month))
+	for p, v := range monthNames {
+		if v[0] == month || v[1] == month {
+			return p + 1
+		}
+	}
+	return -1
+}
+
+// Разбирает период вида "Сентябрь 19", "сентября 2019", "СЕНТЯБРЬ 2019 г." или "09.2019"
+func ParsePeriod(s string) (Period, error) {
+	var period Period
+
+	if m := rePeriodNumeric.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		year, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return period, fmt.Errorf("cannot parse period '%s': invalid month '%s'", s, m[1])
+		}
+		period.Year = year
+		period.Month = time.Month(month)
+		return period, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) > 2 && strings.HasPrefix(fields[len(fields)-1], "г") {
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) != 2 {
+		return period, fmt.Errorf("cannot parse period '%s': expected month and year", s)
+	}
+
+	month := MonthNameToInt(fields[0])
+	if month < 0 {
+		return period, fmt.Errorf("cannot parse period '%s': unknown month '%s'", s, fields[0])
+	}
+
+	yearStr := strings.TrimSuffix(fields[1], ".")
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 0 {
+		return period, fmt.Errorf("cannot parse period '%s': invalid year '%s'", s, fields[1])
+	}
+	switch len(yearStr) {
+	case 2:
+		year += 2000
+	case 4:
+	default:
+		return period, fmt.Errorf("cannot parse period '%s': invalid year '%s'", s, fields[1])
+	}
+
+	period.Year = year
+	period.Month = time.Month(month)
+	return period, nil
+}
+
+// Расчётный период в формате ММ.ГГГГ
+func (p Period) String() string {
+	return fmt.Sprintf("%02d.%04d", int(p.Month), p.Year)
+}
+
+// Количество дней в расчётном периоде
+func (p Period) Days() int {
+	return time.Date(p.Year, p.Month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Префикс номера платёжного документа ГГММ
+func (p Period) NumberPrefix() string {
+	return fmt.Sprintf("%02d%02d", p.Year%100, int(p.Month))
+}
@@ -0,0 +1,51 @@
+package pldoc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want Period
+	}{
+		{"Сентябрь 19", Period{2019, time.September}},
+		{"сентября 2019", Period{2019, time.September}},
+		{"СЕНТЯБРЬ 2019 г.", Period{2019, time.September}},
+		{"  май 2020  ", Period{2020, time.May}},
+		{"декабря 2021 г", Period{2021, time.December}},
+		{"09.2019", Period{2019, time.September}},
+		{"1.2020", Period{2020, time.January}},
+	} {
+		got, err := ParsePeriod(tt.s)
+		if err != nil {
+			t.Errorf("ParsePeriod(%q): unexpected error %v", tt.s, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePeriod(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParsePeriodInvalid(t *testing.T) {
+	for _, s := range []string{"", "2019", "Сентябрь", "Брюмер 2019", "13.2019", "00.2019", "сентябрь 201", "сентябрь 19xx"} {
+		if got, err := ParsePeriod(s); err == nil {
+			t.Errorf("ParsePeriod(%q) = %v, want error", s, got)
+		}
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	p := Period{2020, time.February}
+	if got := p.String(); got != "02.2020" {
+		t.Errorf("String() = %q, want %q", got, "02.2020")
+	}
+	if got := p.NumberPrefix(); got != "2002" {
+		t.Errorf("NumberPrefix() = %q, want %q", got, "2002")
+	}
+	if got := p.Days(); got != 29 {
+		t.Errorf("Days() = %d, want 29", got)
+	}
+}
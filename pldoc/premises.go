@@ -0,0 +1,132 @@
+package pldoc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Правило распознавания помещения по тексту (строка адреса в платёжном документе
+// или описание нежилого помещения в реестре помещений)
+type PremisesRule struct {
+	Regexp string `yaml:"regexp"`           // регулярное выражение ({number} - номер помещения), номер - первая группа
+	Type   string `yaml:"type"`             // тип помещения: "live" или "office"
+	Number int    `yaml:"number,omitempty"` // номер помещения, если в выражении нет группы
+
+	re       *regexp.Regexp
+	roomType int
+}
+
+// Правила распознавания помещений (применяются по порядку, до первого совпадения)
+type PremisesRules []*PremisesRule
+
+// Обозначение комнаты в коммунальной квартире: "комн.", "ком." или "комната"
+const roomExpr = `(?i:комната|комн?\.?)`
+
+// Номер помещения: число, литера, дробная часть или второй номер объединённых квартир
+// и комната ("12а", "101/1", "12-13", "5, комн. 2")
+var rePremisesNumber = regexp.MustCompile(`(?i)^(\d+)\s*([а-яёa-z]?)\s*(?:([/-])\s*(\d+))?(?:\s*,?\s*` +
+	roomExpr + `\s*(\d+))?$`)
+
+// Выражение для номера помещения в строке адреса (литера пишется слитно с номером)
+const premisesNumberExpr = `\d+[а-яА-ЯёЁa-zA-Z]?(?:\s*[/-]\s*\d+)?(?:\s*,?\s*` + roomExpr + `\s*\d+)?`
+
+// Латинские буквы, которые набирают вместо похожих русских литер ("12A" вместо "12А")
+var latinLetters = strings.NewReplacer(
+	"a", "а", "b", "в", "c", "с", "e", "е", "h", "н", "k", "к",
+	"m", "м", "o", "о", "p", "р", "t", "т", "x", "х", "y", "у",
+)
+
+// Подстановка в правилах помещений, которая заменяется выражением для номера помещения
+const premisesNumberPlaceholder = "{number}"
+
+// Правила по умолчанию: квартиры "кв. N" и офисы "оф. N"
+func DefaultPremisesRules() PremisesRules {
+	rules := PremisesRules{
+		{Regexp: `кв\. ?(` + premisesNumberPlaceholder + `)`, Type: "live"},
+		{Regexp: `оф\. ?(` + premisesNumberPlaceholder + `)`, Type: "office"},
+	}
+	rules.compile()
+	return rules
+}
+
+// Читает правила распознавания помещений из файла (YAML или JSON)
+func LoadPremisesRules(fileName string) (PremisesRules, error) {
+	var rules PremisesRules
+
+	if err := loadConfigFile(fileName, &rules); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	if err := rules.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return rules, nil
+}
+
+func (rules PremisesRules) compile() error {
+	for _, rule := range rules {
+		roomType, ok := ParseRoomType(rule.Type)
+		if !ok {
+			return fmt.Errorf("rule '%s': invalid premises type '%s'", rule.Regexp, rule.Type)
+		}
+		re, err := regexp.Compile(strings.ReplaceAll(rule.Regexp, premisesNumberPlaceholder, premisesNumberExpr))
+		if err != nil {
+			return fmt.Errorf("rule '%s': %v", rule.Regexp, err)
+		}
+		rule.re = re
+		rule.roomType = roomType
+	}
+	return nil
+}
+
+// Распознаёт помещение по тексту
+func (rules PremisesRules) Match(s string) (room RoomID, found bool) {
+	for _, rule := range rules {
+		m := rule.re.FindStringSubmatch(s)
+		if m == nil {
+			continue
+		}
+		room = RoomID{Number: rule.Number, Type: rule.roomType}
+		if len(m) > 1 {
+			number, ok := ParsePremisesNumber(m[1])
+			if !ok {
+				continue
+			}
+			number.Type = rule.roomType
+			room = number
+		}
+		return room, true
+	}
+	return
+}
+
+// Разбирает номер помещения ("12", "12а", "101/1", "12-13", "5, комн. 2") в идентификацию
+// жилого помещения. Латинская литера заменяется похожей русской ("12A" - это "12а").
+func ParsePremisesNumber(s string) (room RoomID, ok bool) {
+	m := rePremisesNumber.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return room, false
+	}
+	room.Type = RoomTypeLive
+	room.Number, _ = strconv.Atoi(m[1]) // только цифры
+	room.Suffix = latinLetters.Replace(strings.ToLower(m[2]))
+	if m[4] != "" {
+		room.Suffix += m[3] + m[4]
+	}
+	if m[5] != "" {
+		room.Room, _ = strconv.Atoi(m[5])
+	}
+	return room, true
+}
+
+// Преобразует название типа помещения ("live", "office") в его код
+func ParseRoomType(s string) (int, bool) {
+	switch s {
+	case "live":
+		return RoomTypeLive, true
+	case "office":
+		return RoomTypeOffice, true
+	}
+	return 0, false
+}
@@ -0,0 +1,80 @@
+package pldoc
+
+import "testing"
+
+func TestParsePremisesNumber(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want RoomID
+	}{
+		{"12", RoomID{Number: 12, Type: RoomTypeLive}},
+		{"12а", RoomID{Number: 12, Suffix: "а", Type: RoomTypeLive}},
+		{"12 А", RoomID{Number: 12, Suffix: "а", Type: RoomTypeLive}},
+		{"101/1", RoomID{Number: 101, Suffix: "/1", Type: RoomTypeLive}},
+		{"12а/3", RoomID{Number: 12, Suffix: "а/3", Type: RoomTypeLive}},
+		{"12A", RoomID{Number: 12, Suffix: "а", Type: RoomTypeLive}},
+		{"12 b", RoomID{Number: 12, Suffix: "в", Type: RoomTypeLive}},
+		{"12d", RoomID{Number: 12, Suffix: "d", Type: RoomTypeLive}},
+		{"12-13", RoomID{Number: 12, Suffix: "-13", Type: RoomTypeLive}},
+		{"12 - 13", RoomID{Number: 12, Suffix: "-13", Type: RoomTypeLive}},
+		{"5, комн. 2", RoomID{Number: 5, Room: 2, Type: RoomTypeLive}},
+		{"5 ком.3", RoomID{Number: 5, Room: 3, Type: RoomTypeLive}},
+		{"5, комната 2", RoomID{Number: 5, Room: 2, Type: RoomTypeLive}},
+		{"5, Комната 2", RoomID{Number: 5, Room: 2, Type: RoomTypeLive}},
+	} {
+		got, ok := ParsePremisesNumber(tt.s)
+		if !ok {
+			t.Errorf("ParsePremisesNumber(%q): not parsed", tt.s)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePremisesNumber(%q) = %#v, want %#v", tt.s, got, tt.want)
+		}
+	}
+	for _, s := range []string{"", "а12", "12аб", "12ab", "12-", "5, кухня 2"} {
+		if got, ok := ParsePremisesNumber(s); ok {
+			t.Errorf("ParsePremisesNumber(%q) = %+v, want not parsed", s, got)
+		}
+	}
+}
+
+func TestPremisesRulesMatch(t *testing.T) {
+	rules := DefaultPremisesRules()
+	for _, tt := range []struct {
+		s    string
+		want RoomID
+	}{
+		{"ул. Ленина, д. 1, кв. 12", RoomID{Number: 12, Type: RoomTypeLive}},
+		{"ул. Ленина, д. 1, кв.12а", RoomID{Number: 12, Suffix: "а", Type: RoomTypeLive}},
+		{"ул. Ленина, д. 1, кв. 101/1", RoomID{Number: 101, Suffix: "/1", Type: RoomTypeLive}},
+		{"ул. Ленина, д. 1, кв. 12A", RoomID{Number: 12, Suffix: "а", Type: RoomTypeLive}},
+		{"ул. Ленина, д. 1, кв. 12-13", RoomID{Number: 12, Suffix: "-13", Type: RoomTypeLive}},
+		{"ул. Ленина, д. 1, кв. 5, комн. 2", RoomID{Number: 5, Room: 2, Type: RoomTypeLive}},
+		{"ул. Ленина, д. 1, кв. 5, комната 2", RoomID{Number: 5, Room: 2, Type: RoomTypeLive}},
+		{"ул. Ленина, д. 1, кв. 12 г. Новосибирск", RoomID{Number: 12, Type: RoomTypeLive}},
+		{"ул. Ленина, д. 1, оф. 3", RoomID{Number: 3, Type: RoomTypeOffice}},
+	} {
+		got, ok := rules.Match(tt.s)
+		if !ok {
+			t.Errorf("Match(%q): not found", tt.s)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Match(%q) = %#v, want %#v", tt.s, got, tt.want)
+		}
+	}
+	if got, ok := rules.Match("ул. Ленина, д. 1"); ok {
+		t.Errorf("Match without premises number = %+v, want not found", got)
+	}
+}
+
+func TestPremisesRulesPlaceholder(t *testing.T) {
+	rules := PremisesRules{{Regexp: `пом\. ?({number})`, Type: "office"}}
+	if err := rules.compile(); err != nil {
+		t.Fatal(err)
+	}
+	want := RoomID{Number: 7, Suffix: "б", Type: RoomTypeOffice}
+	if got, ok := rules.Match("ул. Ленина, д. 1, пом. 7б"); !ok || got != want {
+		t.Errorf("Match = %+v, %v, want %+v", got, ok, want)
+	}
+}
@@ -0,0 +1,103 @@
+package pldoc
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Знак перерасчёта в правиле
+const (
+	SignAny      = ""  // любой
+	SignPositive = "+" // доначисление
+	SignNegative = "-" // снятие
+)
+
+// Правило определения основания перерасчёта по строке услуги.
+// Пустые условия подходят к любой строке.
+type RecalculationRule struct {
+	Service string `yaml:"service,omitempty"` // регулярное выражение для названия услуги в биллинговой программе
+	Sign    string `yaml:"sign,omitempty"`    // знак перерасчёта: "+", "-" или пусто
+	Comment string `yaml:"comment,omitempty"` // регулярное выражение для комментария к строке услуги
+	Basis   string `yaml:"basis"`             // основание перерасчёта для ГИС ЖКХ
+
+	serviceRe *regexp.Regexp
+	commentRe *regexp.Regexp
+}
+
+// Правила определения оснований перерасчётов (применяются по порядку, до первого совпадения)
+type RecalculationRules []*RecalculationRule
+
+// Читает правила определения оснований перерасчётов из файла (YAML или JSON)
+func LoadRecalculationRules(fileName string) (RecalculationRules, error) {
+	var rules RecalculationRules
+
+	if err := loadConfigFile(fileName, &rules); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	if err := rules.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return rules, nil
+}
+
+func (rules RecalculationRules) compile() error {
+	for i, rule := range rules {
+		if rule.Basis == "" {
+			return fmt.Errorf("rule %d: basis is empty", i+1)
+		}
+		switch rule.Sign {
+		case SignAny, SignPositive, SignNegative:
+		default:
+			return fmt.Errorf("rule %d: invalid sign '%s'", i+1, rule.Sign)
+		}
+		var err error
+		if rule.serviceRe, err = compileOptional(rule.Service); err != nil {
+			return fmt.Errorf("rule %d: %v", i+1, err)
+		}
+		if rule.commentRe, err = compileOptional(rule.Comment); err != nil {
+			return fmt.Errorf("rule %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func compileOptional(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// Основание перерасчёта для строки услуги (false, если перерасчёта нет или ни одно правило не подошло)
+func (rules RecalculationRules) Basis(line *ServiceLine) (string, bool) {
+	if line.Recalculation == 0 {
+		return "", false
+	}
+	for _, rule := range rules {
+		if rule.serviceRe != nil && !rule.serviceRe.MatchString(line.Name) {
+			continue
+		}
+		if rule.Sign == SignPositive && line.Recalculation < 0 || rule.Sign == SignNegative && line.Recalculation > 0 {
+			continue
+		}
+		if rule.commentRe != nil && !rule.commentRe.MatchString(line.Comment) {
+			continue
+		}
+		return rule.Basis, true
+	}
+	return "", false
+}
+
+// Проверяет, что для каждого перерасчёта по услуге указано основание
+func CheckRecalculations(doc *PaymentDocument) []Problem {
+	var problems []Problem
+
+	for i := range doc.Services {
+		line := &doc.Services[i]
+		if line.Recalculation != 0 && line.RecalculationBasis == "" {
+			problems = append(problems, doc.warning("Основания перерасчетов",
+				fmt.Sprintf("%s: no basis for recalculation %s", line.Name, line.Recalculation)))
+		}
+	}
+	return problems
+}
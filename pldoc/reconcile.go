@@ -0,0 +1,61 @@
+package pldoc
+
+import "fmt"
+
+// Допустимые расхождения при сверке сумм платёжного документа, руб.
+type Tolerance struct {
+	Total  Money // сумма строк документа и строка "Итого"
+	Charge Money // объём * тариф и начисленная сумма по строке услуги
+}
+
+func DefaultTolerance() Tolerance {
+	return Tolerance{Total: 1, Charge: 50}
+}
+
+// Сверяет суммы платёжного документа: сумма к оплате по услугам, неустойкам
+// и взносу на капитальный ремонт должна совпадать с итоговой суммой документа,
+// а начисление по каждой услуге - с произведением объёма на тариф.
+// Расхождения возвращаются как предупреждения.
+func Reconcile(doc *PaymentDocument, tol Tolerance) []Problem {
+	var problems []Problem
+
+	for i := range doc.Services {
+		line := &doc.Services[i]
+		if line.Volume == 0 || line.Price == 0 {
+			continue
+		}
+		expected := line.Price.Mul(line.Volume)
+		if (expected - line.Charged).Abs() > tol.Charge {
+			problems = append(problems, doc.warning(line.Name,
+				fmt.Sprintf("volume %.2f * price %s = %s, charged %s", line.Volume, line.Price, expected, line.Charged)))
+		}
+	}
+	if sum := doc.linesTotal(); (sum - doc.Total).Abs() > tol.Total {
+		problems = append(problems, doc.warning("Итого",
+			fmt.Sprintf("sum of lines %s differs from document total %s by %s", sum, doc.Total, sum-doc.Total)))
+	}
+	return problems
+}
+
+// Сумма к оплате по строкам документа: услуги, неустойки и взнос на капитальный ремонт
+func (doc *PaymentDocument) linesTotal() Money {
+	sum := doc.CapitalRepair.Total
+	for i := range doc.Services {
+		sum += doc.Services[i].Total
+	}
+	for i := range doc.Penalties {
+		sum += doc.Penalties[i].Amount
+	}
+	return sum
+}
+
+func (doc *PaymentDocument) warning(field string, message string) Problem {
+	return Problem{
+		SourceFile: doc.SourceFile,
+		DocNumber:  doc.Number(),
+		Room:       doc.Room.String(),
+		Field:      field,
+		Message:    message,
+		Warning:    true,
+	}
+}
@@ -0,0 +1,455 @@
+package pldoc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+// Соответствие помещения его Идентификатору помещения в ГИС ЖКХ
+type RoomUniqId map[RoomID]string
+
+// Лицевой счёт помещения из реестра ЕЛС
+type ZhkuAccount struct {
+	ID      string  // Идентификатор ЖКУ
+	Account string  // номер лицевого счёта (пустой, если нет в реестре)
+	Share   float64 // доля в праве собственности (0 - не указана)
+	Closed  bool    // лицевой счёт закрыт (архивный)
+}
+
+// Соответствие Идентификатора помещения его лицевым счетам
+// (при долевой собственности у помещения может быть несколько лицевых счетов)
+type UniqIdAccount map[string][]ZhkuAccount
+
+// Площади и количество проживающих по помещениям из реестра помещений
+type RoomInfo map[RoomID]PremisesInfo
+
+// Источник площадей и количества проживающих, который используется при расхождении
+const (
+	InfoFromDocument = "document" // платёжный документ (по умолчанию)
+	InfoFromRegistry = "registry" // реестр помещений
+)
+
+// Реестры идентификаторов, выгруженные из ГИС ЖКХ
+type Registry struct {
+	Rooms    RoomUniqId
+	Accounts UniqIdAccount // действующие лицевые счета
+	Info     RoomInfo
+
+	// помещения, у которых в реестре ЕЛС только закрытые лицевые счета
+	Closed UniqIdAccount
+	// помещения, которым в реестре помещений соответствуют разные Идентификаторы помещения
+	DuplicateRooms map[RoomID][]string
+
+	// правила распознавания нежилых помещений (офисов, пристроек) по их описанию в реестре
+	Premises PremisesRules
+	// дома (если заданы, то дом определяется по ГУИД ФИАС или адресу в реестре)
+	Houses Houses
+
+	// источник площадей и количества проживающих при расхождении документа и реестра
+	InfoSource string
+
+	// расположение данных в реестрах
+	Layout *RegistryLayout
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		Rooms:          make(RoomUniqId),
+		Accounts:       make(UniqIdAccount),
+		Info:           make(RoomInfo),
+		Closed:         make(UniqIdAccount),
+		DuplicateRooms: make(map[RoomID][]string),
+		Premises:       DefaultPremisesRules(),
+		InfoSource:     InfoFromDocument,
+		Layout:         DefaultRegistryLayout(),
+	}
+}
+
+// Заполняет в документе Идентификатор помещения и Идентификатор ЖКУ, а также площади
+// и количество проживающих, которых нет в документе. Возвращает предупреждения
+// о расхождении значений в документе и в реестре помещений.
+// Если у помещения несколько лицевых счетов, то Идентификатор ЖКУ заполняется только
+// для счёта с номером лицевого счёта документа (иначе документ делится по долям, см. Split).
+func (reg *Registry) Resolve(doc *PaymentDocument) []Problem {
+	doc.PremisesID = reg.Rooms[doc.Room]
+	doc.ZhkuID = ""
+	if acc, ok := reg.selectAccount(doc); ok {
+		doc.ZhkuID = acc.ID
+	}
+
+	info, ok := reg.Info[doc.Room]
+	if !ok {
+		return nil
+	}
+	var problems []Problem
+	preferRegistry := reg.InfoSource == InfoFromRegistry
+	for _, v := range []struct {
+		field    string
+		docVal   *float64
+		regVal   float64
+		decimals int
+	}{
+		{"Общая площадь для ЛС", &doc.Area, info.Area, 2},
+		{"Жилая площадь", &doc.LivingArea, info.LivingArea, 2},
+		{"Отапливаемая площадь", &doc.HeatedArea, info.HeatedArea, 2},
+	} {
+		if *v.docVal != 0 && v.regVal != 0 && math.Abs(*v.docVal-v.regVal) >= 0.005 {
+			problems = append(problems, doc.warning(v.field, fmt.Sprintf("document %s, registry %s, using %s",
+				strconv.FormatFloat(*v.docVal, 'f', v.decimals, 64), strconv.FormatFloat(v.regVal, 'f', v.decimals, 64), reg.InfoSource)))
+		}
+		if v.regVal != 0 && (*v.docVal == 0 || preferRegistry) {
+			*v.docVal = v.regVal
+		}
+	}
+	if doc.Residents != 0 && info.Residents != 0 && doc.Residents != info.Residents {
+		problems = append(problems, doc.warning("Количество проживающих", fmt.Sprintf("document %d, registry %d, using %s",
+			doc.Residents, info.Residents, reg.InfoSource)))
+	}
+	if info.Residents != 0 && (doc.Residents == 0 || preferRegistry) {
+		doc.Residents = info.Residents
+	}
+	return problems
+}
+
+// Читает реестр помещений (листы "Идентификатор...", см. RoomsLayout).
+// Возвращает конфликты: помещения, которым в реестре соответствуют разные Идентификаторы
+// помещения (например, квартиры с одинаковыми номерами в разных домах, если дома не заданы).
+// Такие помещения не сопоставляются ни одному Идентификатору помещения.
+// Строки с ошибками (например, с нераспознанным номером квартиры) пропускаются
+// и возвращаются как предупреждения.
+func (reg *Registry) LoadRooms(excelIDs string) ([]Problem, error) {
+	rows, problems, err := initRoomToIdzkuFromFile(excelIDs, &reg.Layout.Rooms, reg.Premises, reg.Houses)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if ids, ok := reg.DuplicateRooms[row.room]; ok {
+			reg.DuplicateRooms[row.room] = appendID(ids, row.id)
+			continue
+		}
+		id, ok := reg.Rooms[row.room]
+		if !ok {
+			reg.Rooms[row.room] = row.id
+			reg.Info[row.room] = row.info
+			continue
+		}
+		if id != row.id {
+			delete(reg.Rooms, row.room)
+			delete(reg.Info, row.room)
+			reg.DuplicateRooms[row.room] = []string{id, row.id}
+		}
+	}
+	// о каждом помещении сообщается один раз, в порядке строк реестра
+	reported := make(map[RoomID]bool)
+	for _, row := range rows {
+		ids, ok := reg.DuplicateRooms[row.room]
+		if !ok || reported[row.room] {
+			continue
+		}
+		reported[row.room] = true
+		problems = append(problems, Problem{
+			SourceFile: excelIDs,
+			Room:       row.room.String(),
+			Field:      "Идентификатор помещения",
+			Message:    fmt.Sprintf("several premises IDs: %s", strings.Join(ids, ", ")),
+			Err:        ErrDuplicatePremises,
+		})
+	}
+	return problems, nil
+}
+
+// Лицевой счёт документа: единственный действующий счёт помещения
+// или счёт с номером лицевого счёта, указанным в документе
+func (reg *Registry) selectAccount(doc *PaymentDocument) (ZhkuAccount, bool) {
+	accounts := reg.Accounts[doc.PremisesID]
+	if len(accounts) == 1 {
+		return accounts[0], true
+	}
+	for _, acc := range accounts {
+		if acc.Account != "" && acc.Account == doc.Account {
+			return acc, true
+		}
+	}
+	return ZhkuAccount{}, false
+}
+
+// Читает реестр единых лицевых счетов (лист "Шаблон экспорта ЕЛС", см. AccountsLayout).
+// Возвращает найденные в реестре конфликты: помещения с несколькими действующими
+// лицевыми счетами без долей и помещения, у которых все лицевые счета закрыты.
+func (reg *Registry) LoadAccounts(excelIDs string) ([]Problem, error) {
+	accounts, err := initIDZhkuToElsFromFile(excelIDs, &reg.Layout.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	premisesIDs := make([]string, 0, len(accounts))
+	for id := range accounts {
+		premisesIDs = append(premisesIDs, id)
+	}
+	sort.Strings(premisesIDs)
+
+	var problems []Problem
+	for _, premisesID := range premisesIDs {
+		var active, closed []ZhkuAccount
+		for _, acc := range accounts[premisesID] {
+			if acc.Closed {
+				closed = appendAccount(closed, acc)
+			} else {
+				active = appendAccount(active, acc)
+			}
+		}
+		problem := Problem{
+			SourceFile: excelIDs,
+			Room:       "premises " + premisesID,
+			Field:      "Идентификатор ЖКУ",
+			Warning:    true,
+		}
+		if len(active) == 0 {
+			reg.Closed[premisesID] = closed
+			problem.Message = fmt.Sprintf("all accounts are closed: %s", accountIDs(closed))
+			problem.Err = ErrClosedAccount
+			problems = append(problems, problem)
+			continue
+		}
+		reg.Accounts[premisesID] = active
+		if len(active) > 1 && !sharesComplete(active) {
+			problem.Message = fmt.Sprintf("several accounts without shares: %s, "+
+				"documents are accepted only by account number", accountIDs(active))
+			problem.Err = ErrDuplicateAccount
+			problems = append(problems, problem)
+		}
+	}
+	return problems, nil
+}
+
+// Характеристики помещения из строки реестра
+func (cols *roomsColumns) readInfo(xlRow *xlsx.Row) (info PremisesInfo, col int, err error) {
+	var residents float64
+	for _, v := range []struct {
+		col int
+		val *float64
+	}{
+		{cols.area, &info.Area},
+		{cols.livingArea, &info.LivingArea},
+		{cols.heatedArea, &info.HeatedArea},
+		{cols.residents, &residents},
+	} {
+		if *v.val, err = ParseNumber(rowCell(xlRow, v.col)); err != nil {
+			return info, v.col, err
+		}
+	}
+	info.Residents = int(math.Round(residents))
+	return info, -1, nil
+}
+
+// Дом строки реестра: по ГУИД ФИАС, если есть такая колонка, иначе по адресу
+func (cols *roomsColumns) findHouse(xlRow *xlsx.Row, houses Houses) (*House, bool) {
+	if fias := rowCell(xlRow, cols.house); fias != "" {
+		if h, ok := houses.ByFIAS(fias); ok {
+			return h, true
+		}
+	}
+	return houses.MatchAddress(rowCell(xlRow, cols.address))
+}
+
+// Строка реестра помещений
+type registryRoom struct {
+	room RoomID
+	id   string // Идентификатор помещения
+	info PremisesInfo
+}
+
+// Строка реестра помещений, которая не может быть прочитана (пропускается при загрузке)
+func invalidRoomRow(excelIDs string, room string, field string, e *ParseError) Problem {
+	return Problem{
+		SourceFile: excelIDs,
+		Room:       room,
+		Field:      field,
+		Message:    e.Error(),
+		Warning:    true,
+		Err:        e,
+	}
+}
+
+// Читает строки реестра помещений (в порядке листов и строк).
+// Строки с ошибками пропускаются и возвращаются как предупреждения.
+func initRoomToIdzkuFromFile(excelIDs string, layout *RoomsLayout, rules PremisesRules,
+	houses Houses) ([]registryRoom, []Problem, error) {
+	var (
+		room     RoomID
+		rows     []registryRoom
+		problems []Problem
+	)
+
+	xlFile, err := xlsx.OpenFile(excelIDs)
+	if err != nil {
+		return nil, nil, &ParseError{File: excelIDs, Row: -1, Col: -1, Err: err}
+	}
+	for _, xlSheet := range xlFile.Sheets {
+		if !strings.HasPrefix(xlSheet.Name, layout.SheetPrefix) {
+			continue
+		}
+		cols := layout.columns()
+		headerFound := false
+		dataFound := false
+		for i, xlRow := range xlSheet.Rows {
+			if xlRow == nil {
+				continue
+			}
+			if !headerFound && !dataFound && cols.findHeaders(layout, xlRow) {
+				headerFound = true
+				continue
+			}
+
+			id := rowCell(xlRow, cols.id)
+			if id == "" || !layout.matchAddress(rowCell(xlRow, cols.address)) {
+				continue
+			}
+			roomStr := rowCell(xlRow, cols.room)
+			officeStr := rowCell(xlRow, cols.office)
+			if roomStr == "" && officeStr == "" {
+				continue
+			}
+
+			if roomStr != "" {
+				// это квартира (или комната в коммунальной квартире)
+				var ok bool
+				room, ok = ParsePremisesNumber(roomStr)
+				if !ok {
+					if !dataFound {
+						// строка заголовков, которую не удалось распознать
+						continue
+					}
+					problems = append(problems, invalidRoomRow(excelIDs, roomStr, "Номер квартиры",
+						&ParseError{Sheet: xlSheet.Name, Row: i, Col: cols.room, Text: roomStr, Err: ErrInvalidNumber}))
+					continue
+				}
+				if roomNumberStr := rowCell(xlRow, cols.roomNumber); roomNumberStr != "" {
+					room.Room, err = strconv.Atoi(roomNumberStr)
+					if err != nil {
+						problems = append(problems, invalidRoomRow(excelIDs, roomStr, "Номер комнаты",
+							&ParseError{Sheet: xlSheet.Name, Row: i, Col: cols.roomNumber, Text: roomNumberStr,
+								Err: ErrInvalidNumber}))
+						continue
+					}
+				}
+			} else {
+				// это офис (или другое нежилое помещение, например, пристройка)
+				var found bool
+				room, found = rules.Match(officeStr)
+				if !found {
+					continue
+				}
+			}
+			dataFound = true
+			if len(houses) > 0 {
+				house, ok := cols.findHouse(xlRow, houses)
+				if !ok {
+					// дом не обслуживается
+					continue
+				}
+				room.House = house.Key()
+			}
+			info, col, err := cols.readInfo(xlRow)
+			if err != nil {
+				problems = append(problems, invalidRoomRow(excelIDs, room.String(), "Характеристики помещения",
+					&ParseError{Sheet: xlSheet.Name, Row: i, Col: col, Text: rowCell(xlRow, col), Err: ErrInvalidNumber}))
+				continue
+			}
+			rows = append(rows, registryRoom{room: room, id: id, info: info})
+		}
+	}
+	return rows, problems, nil
+}
+
+// Читает лицевые счета из реестра ЕЛС, сгруппированные по Идентификатору помещения
+func initIDZhkuToElsFromFile(excelIDs string, layout *AccountsLayout) (UniqIdAccount, error) {
+	xlFile, err := xlsx.OpenFile(excelIDs)
+	if err != nil {
+		return nil, &ParseError{File: excelIDs, Row: -1, Col: -1, Err: err}
+	}
+	xlSheet, ok := xlFile.Sheet[layout.SheetName]
+	if !ok {
+		return nil, &ParseError{File: excelIDs, Sheet: layout.SheetName, Row: -1, Col: -1, Err: ErrSheetNotFound}
+	}
+
+	// строка заголовков (если её нет, то используются номера колонок из расположения)
+	cols := layout.columns()
+	headerRow := -1
+	for i, xlRow := range xlSheet.Rows {
+		if xlRow != nil && cols.findHeaders(layout, xlRow) {
+			headerRow = i
+			break
+		}
+	}
+
+	accounts := make(UniqIdAccount)
+	for i, xlRow := range xlSheet.Rows {
+		if xlRow == nil || i <= headerRow {
+			continue
+		}
+		zhkuID := rowCell(xlRow, cols.zhkuID)
+		premisesID := rowCell(xlRow, cols.premisesID)
+		if zhkuID == "" || premisesID == "" || isHeaderRow(zhkuID, premisesID, layout) {
+			continue
+		}
+		shareStr := rowCell(xlRow, cols.share)
+		share, err := ParseShare(shareStr)
+		if err != nil {
+			return nil, &ParseError{File: excelIDs, Sheet: xlSheet.Name, Row: i, Col: cols.share,
+				Text: shareStr, Err: ErrInvalidNumber}
+		}
+		accounts[premisesID] = append(accounts[premisesID], ZhkuAccount{
+			ID:      zhkuID,
+			Account: rowCell(xlRow, cols.account),
+			Share:   share,
+			Closed:  layout.isClosed(rowCell(xlRow, cols.status)),
+		})
+	}
+	return accounts, nil
+}
+
+// Является ли строка повторной строкой заголовков или строкой с номерами колонок
+func isHeaderRow(zhkuID string, premisesID string, layout *AccountsLayout) bool {
+	if normalizeLabel(zhkuID) == normalizeLabel(layout.ZhkuID.Header) ||
+		normalizeLabel(premisesID) == normalizeLabel(layout.PremisesID.Header) {
+		return true
+	}
+	_, err1 := strconv.Atoi(zhkuID)
+	_, err2 := strconv.Atoi(premisesID)
+	return err1 == nil && err2 == nil
+}
+
+// Добавляет идентификатор в список, если его там ещё нет
+func appendID(ids []string, id string) []string {
+	for _, v := range ids {
+		if v == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+// Добавляет лицевой счёт в список, если счёта с таким Идентификатором ЖКУ там ещё нет
+func appendAccount(list []ZhkuAccount, acc ZhkuAccount) []ZhkuAccount {
+	for _, v := range list {
+		if v.ID == acc.ID {
+			return list
+		}
+	}
+	return append(list, acc)
+}
+
+// Идентификаторы ЖКУ лицевых счетов через запятую
+func accountIDs(accounts []ZhkuAccount) string {
+	ids := make([]string, len(accounts))
+	for i, acc := range accounts {
+		ids[i] = acc.ID
+	}
+	return strings.Join(ids, ", ")
+}
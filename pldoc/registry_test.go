@@ -0,0 +1,128 @@
+package pldoc
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+// Записывает книгу с одним листом во временный каталог теста
+func writeWorkbook(t *testing.T, sheetName string, rows [][]string) string {
+	t.Helper()
+	xlFile := xlsx.NewFile()
+	xlSheet, err := xlFile.AddSheet(sheetName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		xlRow := xlSheet.AddRow()
+		for _, v := range row {
+			xlRow.AddCell().SetString(v)
+		}
+	}
+	fileName := filepath.Join(t.TempDir(), "registry.xlsx")
+	if err := xlFile.Save(fileName); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+var twoHouseRooms = [][]string{
+	{"Адрес", "Номер квартиры", "Номер нежилого помещения", "Идентификатор помещения"},
+	{"ул. Ленина, д. 1", "12", "", "ID-A12"},
+	{"ул. Ленина, д. 1", "14", "", "ID-A14"},
+	{"ул. Ленина, д. 3", "12", "", "ID-B12"},
+	{"ул. Ленина, д. 3", "12", "", "ID-B12"},
+}
+
+func TestLoadRoomsDuplicates(t *testing.T) {
+	reg := NewRegistry()
+	problems, err := reg.LoadRooms(writeWorkbook(t, "Идентификаторы", twoHouseRooms))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	room12 := RoomID{Number: 12, Type: RoomTypeLive}
+	if id, ok := reg.Rooms[room12]; ok {
+		t.Errorf("duplicate room 12 is mapped to %s", id)
+	}
+	if id := reg.Rooms[RoomID{Number: 14, Type: RoomTypeLive}]; id != "ID-A14" {
+		t.Errorf("room 14 = %q, want ID-A14", id)
+	}
+	if len(problems) != 1 || !errors.Is(problems[0].Err, ErrDuplicatePremises) || problems[0].Warning {
+		t.Fatalf("problems = %+v, want one duplicate premises error", problems)
+	}
+
+	// документ по такому помещению не выгружается
+	doc := &PaymentDocument{Room: room12}
+	reg.Resolve(doc)
+	docProblems := reg.Validate(doc)
+	if len(docProblems) == 0 || !errors.Is(docProblems[0].Err, ErrDuplicatePremises) {
+		t.Errorf("Validate = %+v, want duplicate premises error", docProblems)
+	}
+}
+
+func TestLoadRoomsTwoHouses(t *testing.T) {
+	houses := Houses{
+		{Code: "A", Address: `д\. 1\b`},
+		{Code: "B", Address: `д\. 3\b`},
+	}
+	if err := houses.compile(); err != nil {
+		t.Fatal(err)
+	}
+	reg := NewRegistry()
+	reg.Houses = houses
+	problems, err := reg.LoadRooms(writeWorkbook(t, "Идентификаторы", twoHouseRooms))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("unexpected problems %+v", problems)
+	}
+	for _, tt := range []struct {
+		room RoomID
+		want string
+	}{
+		{RoomID{House: "A", Number: 12, Type: RoomTypeLive}, "ID-A12"},
+		{RoomID{House: "A", Number: 14, Type: RoomTypeLive}, "ID-A14"},
+		{RoomID{House: "B", Number: 12, Type: RoomTypeLive}, "ID-B12"},
+	} {
+		if got := reg.Rooms[tt.room]; got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.room, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRoomsSkipsInvalidRows(t *testing.T) {
+	rows := [][]string{
+		{"Адрес", "Номер квартиры", "Номер нежилого помещения", "Идентификатор помещения"},
+		{"ул. Ленина, д. 1", "11", "", "ID-11"},
+		{"ул. Ленина, д. 1", "12A", "", "ID-12A"},
+		{"ул. Ленина, д. 1", "12-13", "", "ID-12-13"},
+		{"ул. Ленина, д. 1", "14 (бывш. 15)", "", "ID-14"},
+		{"ул. Ленина, д. 1", "16", "", "ID-16"},
+	}
+	reg := NewRegistry()
+	problems, err := reg.LoadRooms(writeWorkbook(t, "Идентификаторы", rows))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || !problems[0].Warning || !errors.Is(problems[0].Err, ErrInvalidNumber) {
+		t.Fatalf("problems = %+v, want one invalid number warning", problems)
+	}
+	for _, tt := range []struct {
+		room RoomID
+		want string
+	}{
+		{RoomID{Number: 11, Type: RoomTypeLive}, "ID-11"},
+		{RoomID{Number: 12, Suffix: "а", Type: RoomTypeLive}, "ID-12A"},
+		{RoomID{Number: 12, Suffix: "-13", Type: RoomTypeLive}, "ID-12-13"},
+		{RoomID{Number: 16, Type: RoomTypeLive}, "ID-16"},
+	} {
+		if got := reg.Rooms[tt.room]; got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.room, got, tt.want)
+		}
+	}
+}
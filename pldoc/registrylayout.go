@@ -0,0 +1,250 @@
+package pldoc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+// Колонка реестра: ищется по тексту заголовка (без учёта регистра, сначала точное совпадение,
+// затем вхождение); если заголовок не найден, то используется номер колонки Col (-1 - колонки нет)
+type RegistryColumn struct {
+	Header string `yaml:"header,omitempty"`
+	Col    int    `yaml:"col"`
+}
+
+// Расположение данных в реестре помещений, выгруженном из ГИС ЖКХ
+type RoomsLayout struct {
+	SheetPrefix   string `yaml:"sheet_prefix"`             // начало названия листов с помещениями
+	AddressFilter string `yaml:"address_filter,omitempty"` // регулярное выражение для адреса (пусто - все строки)
+
+	Address    RegistryColumn `yaml:"address"`
+	House      RegistryColumn `yaml:"house"`       // ГУИД дома по ФИАС (используется, если заданы дома)
+	Room       RegistryColumn `yaml:"room"`        // номер жилого помещения (квартиры): "12", "12а", "101/1"
+	RoomNumber RegistryColumn `yaml:"room_number"` // номер комнаты в коммунальной квартире
+	Office     RegistryColumn `yaml:"office"`      // нежилое помещение (распознаётся правилами помещений)
+	ID         RegistryColumn `yaml:"id"`          // Идентификатор помещения
+	Area       RegistryColumn `yaml:"area"`
+	LivingArea RegistryColumn `yaml:"living_area"`
+	HeatedArea RegistryColumn `yaml:"heated_area"`
+	Residents  RegistryColumn `yaml:"residents"`
+
+	addressRe *regexp.Regexp
+}
+
+// Расположение данных в реестре единых лицевых счетов, выгруженном из ГИС ЖКХ
+type AccountsLayout struct {
+	SheetName string `yaml:"sheet_name"` // название листа с лицевыми счетами
+
+	ZhkuID     RegistryColumn `yaml:"zhku_id"`     // Идентификатор ЖКУ
+	PremisesID RegistryColumn `yaml:"premises_id"` // Идентификатор помещения
+	Account    RegistryColumn `yaml:"account"`     // номер лицевого счёта (сравнивается с л/с документа)
+	Share      RegistryColumn `yaml:"share"`       // доля в праве собственности: "1/2", "0,5", "50%"
+	Status     RegistryColumn `yaml:"status"`      // статус лицевого счёта
+
+	// статусы закрытых (архивных) лицевых счетов, сравниваются по вхождению без учёта регистра
+	ClosedStatuses []string `yaml:"closed_statuses,flow"`
+}
+
+// Расположение данных в реестрах, выгруженных из ГИС ЖКХ
+type RegistryLayout struct {
+	Rooms    RoomsLayout    `yaml:"rooms"`
+	Accounts AccountsLayout `yaml:"accounts"`
+}
+
+// Расположение данных в реестрах по умолчанию (номера колонок - как в выгрузке ГИС ЖКХ)
+func DefaultRegistryLayout() *RegistryLayout {
+	return &RegistryLayout{
+		Rooms: RoomsLayout{
+			SheetPrefix: "Идентификатор",
+			Address:     RegistryColumn{Header: "Адрес", Col: 0},
+			House:       RegistryColumn{Header: "ФИАС", Col: -1},
+			Room:        RegistryColumn{Header: "Номер квартиры", Col: 9},
+			RoomNumber:  RegistryColumn{Header: "Номер комнаты", Col: -1},
+			Office:      RegistryColumn{Header: "Номер нежилого помещения", Col: 10},
+			ID:          RegistryColumn{Header: "Идентификатор помещения", Col: 13},
+			Area:        RegistryColumn{Header: "Общая площадь", Col: -1},
+			LivingArea:  RegistryColumn{Header: "Жилая площадь", Col: -1},
+			HeatedArea:  RegistryColumn{Header: "Отапливаемая площадь", Col: -1},
+			Residents:   RegistryColumn{Header: "Количество проживающих", Col: -1},
+		},
+		Accounts: AccountsLayout{
+			SheetName:      "Шаблон экспорта ЕЛС",
+			ZhkuID:         RegistryColumn{Header: "Идентификатор ЖКУ", Col: 2},
+			PremisesID:     RegistryColumn{Header: "Идентификатор помещения", Col: 3},
+			Account:        RegistryColumn{Header: "Номер ЛС", Col: -1},
+			Share:          RegistryColumn{Header: "Доля", Col: -1},
+			Status:         RegistryColumn{Header: "Статус", Col: -1},
+			ClosedStatuses: []string{"Закрыт", "Архив"},
+		},
+	}
+}
+
+// Читает расположение данных в реестрах из файла (YAML или JSON),
+// незаданные значения берутся из расположения по умолчанию
+func LoadRegistryLayout(fileName string) (*RegistryLayout, error) {
+	var raw interface{}
+
+	if err := loadConfigFile(fileName, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	layout := DefaultRegistryLayout()
+	if raw != nil {
+		if err := mergeConfigNode(raw, layout); err != nil {
+			return nil, fmt.Errorf("%s: %v", fileName, err)
+		}
+	}
+	if err := layout.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return layout, nil
+}
+
+func (layout *RegistryLayout) compile() error {
+	rooms := &layout.Rooms
+	rooms.addressRe = nil
+	if rooms.AddressFilter != "" {
+		re, err := regexp.Compile(rooms.AddressFilter)
+		if err != nil {
+			return fmt.Errorf("address filter: %v", err)
+		}
+		rooms.addressRe = re
+	}
+	return nil
+}
+
+// Подходит ли адрес под фильтр адресов
+func (rooms *RoomsLayout) matchAddress(address string) bool {
+	return rooms.addressRe == nil || rooms.addressRe.MatchString(address)
+}
+
+// Колонки реестра помещений, определённые для листа
+type roomsColumns struct {
+	address, house, room, roomNumber, office, id int
+	area, livingArea, heatedArea, residents      int
+}
+
+func (rooms *RoomsLayout) columns() roomsColumns {
+	return roomsColumns{
+		address:    rooms.Address.Col,
+		house:      rooms.House.Col,
+		room:       rooms.Room.Col,
+		roomNumber: rooms.RoomNumber.Col,
+		office:     rooms.Office.Col,
+		id:         rooms.ID.Col,
+		area:       rooms.Area.Col,
+		livingArea: rooms.LivingArea.Col,
+		heatedArea: rooms.HeatedArea.Col,
+		residents:  rooms.Residents.Col,
+	}
+}
+
+// Ищет в строке заголовки колонок. Если строка похожа на строку заголовков
+// (найдено не меньше двух заголовков), то запоминает найденные колонки и возвращает true.
+func (cols *roomsColumns) findHeaders(rooms *RoomsLayout, xlRow *xlsx.Row) bool {
+	return findHeaders(xlRow, []headerColumn{
+		{&rooms.Address, &cols.address},
+		{&rooms.House, &cols.house},
+		{&rooms.Room, &cols.room},
+		{&rooms.RoomNumber, &cols.roomNumber},
+		{&rooms.Office, &cols.office},
+		{&rooms.ID, &cols.id},
+		{&rooms.Area, &cols.area},
+		{&rooms.LivingArea, &cols.livingArea},
+		{&rooms.HeatedArea, &cols.heatedArea},
+		{&rooms.Residents, &cols.residents},
+	})
+}
+
+// Колонки реестра лицевых счетов, определённые для листа
+type accountsColumns struct {
+	zhkuID, premisesID, account, share, status int
+}
+
+func (accounts *AccountsLayout) columns() accountsColumns {
+	return accountsColumns{
+		zhkuID:     accounts.ZhkuID.Col,
+		premisesID: accounts.PremisesID.Col,
+		account:    accounts.Account.Col,
+		share:      accounts.Share.Col,
+		status:     accounts.Status.Col,
+	}
+}
+
+// Ищет в строке заголовки колонок реестра лицевых счетов (см. roomsColumns.findHeaders)
+func (cols *accountsColumns) findHeaders(accounts *AccountsLayout, xlRow *xlsx.Row) bool {
+	return findHeaders(xlRow, []headerColumn{
+		{&accounts.ZhkuID, &cols.zhkuID},
+		{&accounts.PremisesID, &cols.premisesID},
+		{&accounts.Account, &cols.account},
+		{&accounts.Share, &cols.share},
+		{&accounts.Status, &cols.status},
+	})
+}
+
+// Закрыт ли лицевой счёт с указанным статусом
+func (accounts *AccountsLayout) isClosed(status string) bool {
+	status = normalizeLabel(status)
+	if status == "" {
+		return false
+	}
+	for _, v := range accounts.ClosedStatuses {
+		if v = normalizeLabel(v); v != "" && strings.Contains(status, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Колонка реестра и номер колонки, найденный на листе
+type headerColumn struct {
+	column *RegistryColumn
+	col    *int
+}
+
+// Ищет в строке заголовки колонок и записывает номера найденных колонок,
+// если их не меньше двух (иначе строка не считается строкой заголовков)
+func findHeaders(xlRow *xlsx.Row, columns []headerColumn) bool {
+	found := make(map[*int]int)
+	for _, v := range columns {
+		if col := findHeaderCell(xlRow, v.column.Header); col >= 0 {
+			found[v.col] = col
+		}
+	}
+	if len(found) < 2 {
+		return false
+	}
+	for col, v := range found {
+		*col = v
+	}
+	return true
+}
+
+// Номер колонки с заголовком header (-1, если не найдена)
+func findHeaderCell(xlRow *xlsx.Row, header string) int {
+	header = normalizeLabel(header)
+	if header == "" {
+		return -1
+	}
+	for i, cell := range xlRow.Cells {
+		if normalizeLabel(cell.String()) == header {
+			return i
+		}
+	}
+	for i, cell := range xlRow.Cells {
+		if strings.Contains(normalizeLabel(cell.String()), header) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Текст ячейки строки (пустая строка, если колонки нет)
+func rowCell(xlRow *xlsx.Row, col int) string {
+	if col < 0 || col >= len(xlRow.Cells) || xlRow.Cells[col] == nil {
+		return ""
+	}
+	return strings.TrimSpace(xlRow.Cells[col].String())
+}
@@ -0,0 +1,215 @@
+package pldoc
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Состояние обработки входного файла
+const (
+	StatusOK      = "ok"      // документ выгружен
+	StatusSkipped = "skipped" // документ разобран, но не выгружен из-за ошибок проверки
+	StatusFailed  = "failed"  // документ не удалось разобрать
+)
+
+// Сведения об одном входном файле для итогового отчёта
+type ReportEntry struct {
+	SourceFile    string   `json:"file"`
+	Room          string   `json:"room,omitempty"`
+	DocNumber     string   `json:"doc_number,omitempty"`
+	Total         Money    `json:"total"`
+	ServiceLines  int      `json:"service_lines"`
+	Penalties     Money    `json:"penalties"`
+	CapitalRepair Money    `json:"capital_repair"`
+	Status        string   `json:"status"`
+	Reason        string   `json:"reason,omitempty"` // причина ошибки (для skipped, failed)
+	Warnings      []string `json:"warnings,omitempty"`
+}
+
+// Итог по услуге для выгруженных документов
+type ServiceTotal struct {
+	Name          string `json:"name"`
+	Charged       Money  `json:"charged"`
+	Recalculation Money  `json:"recalculation"`
+	Total         Money  `json:"total"`
+}
+
+// Итоговый отчёт по обработке пакета платёжных документов
+type Report struct {
+	Entries       []ReportEntry  `json:"files"`
+	Services      []ServiceTotal `json:"services"`
+	Penalties     Money          `json:"penalties"`
+	CapitalRepair Money          `json:"capital_repair"`
+	Total         Money          `json:"total"`
+
+	services map[string]int // индекс итога по имени услуги
+}
+
+// Добавляет в отчёт входной файл. doc равен nil, если документ не удалось разобрать (err - причина).
+// Итоги по услугам считаются только по выгружаемым документам.
+func (r *Report) Add(fileName string, doc *PaymentDocument, problems []Problem, err error) {
+	entry := ReportEntry{SourceFile: fileName, Status: StatusOK}
+	for i := range problems {
+		if problems[i].Warning {
+			entry.Warnings = append(entry.Warnings, problems[i].Message)
+		} else if entry.Reason == "" && err == nil {
+			entry.Status = StatusSkipped
+			entry.Reason = problems[i].Message
+		}
+	}
+	if err != nil {
+		entry.Status = StatusFailed
+		entry.Reason = strings.TrimPrefix(err.Error(), fileName+": ")
+	}
+	if doc == nil {
+		r.Entries = append(r.Entries, entry)
+		return
+	}
+
+	entry.Room = doc.Room.String()
+	entry.DocNumber = doc.Number()
+	entry.Total = doc.Total
+	entry.ServiceLines = len(doc.Services)
+	for i := range doc.Penalties {
+		entry.Penalties += doc.Penalties[i].Amount
+	}
+	entry.CapitalRepair = doc.CapitalRepair.Total
+	r.Entries = append(r.Entries, entry)
+
+	if entry.Status != StatusOK {
+		return
+	}
+	if r.services == nil {
+		r.services = make(map[string]int)
+	}
+	for i := range doc.Services {
+		line := &doc.Services[i]
+		idx, ok := r.services[line.Name]
+		if !ok {
+			idx = len(r.Services)
+			r.services[line.Name] = idx
+			r.Services = append(r.Services, ServiceTotal{Name: line.Name})
+		}
+		r.Services[idx].Charged += line.Charged
+		r.Services[idx].Recalculation += line.Recalculation
+		r.Services[idx].Total += line.Total
+	}
+	r.Penalties += entry.Penalties
+	r.CapitalRepair += entry.CapitalRepair
+	r.Total += entry.Total
+}
+
+// Количество файлов с указанным состоянием
+func (r *Report) Count(status string) int {
+	count := 0
+	for i := range r.Entries {
+		if r.Entries[i].Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Количество предупреждений по всем файлам
+func (r *Report) Warnings() int {
+	count := 0
+	for i := range r.Entries {
+		count += len(r.Entries[i].Warnings)
+	}
+	return count
+}
+
+// Выводит отчёт в виде таблицы: сведения о файлах и итоги по услугам
+func (r *Report) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "File\tRoom\tDoc number\tTotal\tLines\tPenalties\tCap. repair\tStatus\tWarnings\t")
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%d\t\n", filepath.Base(e.SourceFile), e.Room, e.DocNumber,
+			e.Total, e.ServiceLines, e.Penalties, e.CapitalRepair, e.Status, len(e.Warnings))
+	}
+	tw.Flush()
+	for i := range r.Entries {
+		if r.Entries[i].Reason != "" {
+			fmt.Fprintf(w, "%s %s: %s\n", strings.ToUpper(r.Entries[i].Status), r.Entries[i].SourceFile, r.Entries[i].Reason)
+		}
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Service\tCharged\tRecalculation\tTotal\t")
+	for _, s := range r.sortedServices() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", s.Name, s.Charged, s.Recalculation, s.Total)
+	}
+	fmt.Fprintf(tw, "Penalties\t\t\t%s\t\n", r.Penalties)
+	fmt.Fprintf(tw, "Capital repair\t\t\t%s\t\n", r.CapitalRepair)
+	fmt.Fprintf(tw, "Total\t\t\t%s\t\n", r.Total)
+	tw.Flush()
+}
+
+func (r *Report) sortedServices() []ServiceTotal {
+	res := append([]ServiceTotal(nil), r.Services...)
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// Сохраняет отчёт в JSON (.json) или CSV (остальные расширения)
+func (r *Report) Save(fileName string) error {
+	if strings.EqualFold(filepath.Ext(fileName), ".json") {
+		return r.saveJSON(fileName)
+	}
+	return r.saveCSV(fileName)
+}
+
+func (r *Report) saveJSON(fileName string) error {
+	rep := *r
+	rep.Services = r.sortedServices()
+	data, err := json.MarshalIndent(&rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, data, 0644)
+}
+
+var reportColumns = []string{"Файл", "Помещение", "Номер платежного документа", "Всего", "Строк услуг",
+	"Пени", "Капитальный ремонт", "Состояние", "Причина", "Предупреждения"}
+
+// CSV (';'): таблица файлов, затем итоги по услугам
+func (r *Report) saveCSV(fileName string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Comma = ';'
+	w.Write(reportColumns)
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		w.Write([]string{e.SourceFile, e.Room, e.DocNumber, e.Total.String(), strconv.Itoa(e.ServiceLines),
+			e.Penalties.String(), e.CapitalRepair.String(), e.Status, e.Reason, strings.Join(e.Warnings, "; ")})
+	}
+	w.Write(nil)
+	w.Write([]string{"Услуга", "Начислено", "Перерасчёт", "Всего"})
+	for _, s := range r.sortedServices() {
+		w.Write([]string{s.Name, s.Charged.String(), s.Recalculation.String(), s.Total.String()})
+	}
+	w.Write([]string{"Пени", "", "", r.Penalties.String()})
+	w.Write([]string{"Капитальный ремонт", "", "", r.CapitalRepair.String()})
+	w.Write([]string{"Итого", "", "", r.Total.String()})
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
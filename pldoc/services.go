@@ -0,0 +1,116 @@
+package pldoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Группа колонок, в которую выводится услуга
+type ServiceGroup string
+
+const (
+	GroupIndividual  ServiceGroup = "individual"  // коммунальная услуга, индивидуальное потребление
+	GroupCommon      ServiceGroup = "common"      // коммунальная услуга при содержании общего имущества
+	GroupAdditional  ServiceGroup = "additional"  // дополнительная услуга
+	GroupMaintenance ServiceGroup = "maintenance" // входит в плату за содержание жилого помещения
+	GroupPenalty     ServiceGroup = "penalty"     // неустойка (выводится на лист "Неустойки")
+)
+
+// Соответствие услуги биллинговой программы услуге ГИС ЖКХ
+type ServiceMapping struct {
+	Name    string       `yaml:"name"`             // название услуги в биллинговой программе (без уточнения в скобках)
+	GisName string       `yaml:"gis_name"`         // название услуги в ГИС ЖКХ (для неустоек - вид начисления)
+	Unit    string       `yaml:"unit,omitempty"`   // единица измерения
+	Method  string       `yaml:"method,omitempty"` // способ определения объемов КУ ("Прибор учета", "Норматив")
+	Group   ServiceGroup `yaml:"group"`
+	Basis   string       `yaml:"basis,omitempty"` // основания начислений (для неустоек)
+}
+
+// Таблица соответствия услуг
+type ServiceMap struct {
+	entries map[string]*ServiceMapping
+}
+
+// Ошибка: в документе есть услуги, отсутствующие в таблице соответствия
+type UnknownServicesError struct {
+	Services []string
+}
+
+func (e *UnknownServicesError) Error() string {
+	return fmt.Sprintf("unknown services: %s", strings.Join(e.Services, ", "))
+}
+
+func (e *UnknownServicesError) Is(target error) bool {
+	return target == ErrUnknownService
+}
+
+// Таблица соответствия услуг по умолчанию
+func DefaultServiceMap() *ServiceMap {
+	return NewServiceMap([]ServiceMapping{
+		{Name: "охрана", GisName: "Оплата охранных услуг", Group: GroupAdditional},
+		{Name: "домофон", GisName: "Запирающее устройство (ЗУ)", Group: GroupAdditional},
+		{Name: "видеодомофон", GisName: "Видеонаблюдение", Group: GroupAdditional},
+		{Name: "холодное водоснабжение", GisName: "Холодное водоснабжение", Unit: "м3", Group: GroupIndividual},
+		{Name: "горячее водоснабжение", GisName: "Горячее водоснабжение", Unit: "м3", Group: GroupIndividual},
+		{Name: "водоотведение", GisName: "Водоотведение", Unit: "м3", Group: GroupIndividual},
+		{Name: "электроэнергия", GisName: "Электроснабжение", Unit: "кВт.ч", Group: GroupIndividual},
+		{Name: "электроэнергия на содерж. ОИ", GisName: "Электрическая энергия", Unit: "кВт.ч", Method: "Прибор учета", Group: GroupCommon},
+		{Name: "горячая вода на содерж.  ОИ", GisName: "Горячая вода", Unit: "м3", Method: "Прибор учета", Group: GroupCommon},
+		{Name: "холодная вода на содерж. ОИ", GisName: "Холодная вода", Unit: "м3", Method: "Прибор учета", Group: GroupCommon},
+		{Name: "текущее содержание", GisName: "Плата за содержание жилого помещения", Unit: "м2", Group: GroupMaintenance},
+		{Name: "пеня", GisName: "Пени", Group: GroupPenalty, Basis: "Пени за просрочку коммунальный платежей"},
+	})
+}
+
+func NewServiceMap(entries []ServiceMapping) *ServiceMap {
+	m := &ServiceMap{entries: make(map[string]*ServiceMapping, len(entries))}
+	for i := range entries {
+		m.entries[entries[i].Name] = &entries[i]
+	}
+	return m
+}
+
+// Читает таблицу соответствия услуг из файла (YAML или JSON)
+func LoadServiceMap(fileName string) (*ServiceMap, error) {
+	var entries []ServiceMapping
+
+	if err := loadConfigFile(fileName, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	for _, v := range entries {
+		switch v.Group {
+		case GroupIndividual, GroupCommon, GroupAdditional, GroupMaintenance, GroupPenalty:
+		default:
+			return nil, fmt.Errorf("%s: service %s: invalid group '%s'", fileName, v.Name, v.Group)
+		}
+	}
+	return NewServiceMap(entries), nil
+}
+
+// Ищет услугу по названию из платёжного документа (уточнение в скобках отбрасывается)
+func (m *ServiceMap) Find(serviceName string) (*ServiceMapping, bool) {
+	if v, ok := m.entries[serviceName]; ok {
+		return v, true
+	}
+
+	pos := strings.Index(serviceName, " (")
+	if pos > 1 {
+		serviceName = serviceName[0:pos]
+	}
+	v, ok := m.entries[serviceName]
+	return v, ok
+}
+
+// Сортирует и удаляет повторы в списке неизвестных услуг
+func uniqueServiceNames(names []string) []string {
+	sort.Strings(names)
+	res := names[:0]
+	for i, v := range names {
+		if i > 0 && v == names[i-1] {
+			continue
+		}
+		res = append(res, v)
+	}
+	return res
+}
@@ -0,0 +1,115 @@
+package pldoc
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Разбирает долю в праве собственности: дробь ("1/2"), проценты ("50%") или число ("0,5").
+// Пустая строка - доля не указана (0).
+func ParseShare(s string) (float64, error) {
+	str := normalizeNumber(s)
+	if str == "" {
+		return 0, nil
+	}
+	var share float64
+	if i := strings.IndexByte(str, '/'); i >= 0 {
+		num, err1 := ParseNumber(str[:i])
+		den, err2 := ParseNumber(str[i+1:])
+		if err1 != nil || err2 != nil || den == 0 {
+			return 0, fmt.Errorf("invalid share '%s'", s)
+		}
+		share = num / den
+	} else if strings.HasSuffix(str, "%") {
+		v, err := ParseNumber(strings.TrimSuffix(str, "%"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid share '%s'", s)
+		}
+		share = v / 100
+	} else {
+		v, err := ParseNumber(str)
+		if err != nil {
+			return 0, fmt.Errorf("invalid share '%s'", s)
+		}
+		share = v
+	}
+	if share <= 0 || share > 1 {
+		return 0, fmt.Errorf("invalid share '%s'", s)
+	}
+	return share, nil
+}
+
+// Указаны ли доли всех лицевых счетов и равна ли их сумма единице
+func sharesComplete(accounts []ZhkuAccount) bool {
+	sum := 0.0
+	for _, acc := range accounts {
+		if acc.Share == 0 {
+			return false
+		}
+		sum += acc.Share
+	}
+	return math.Abs(sum-1) < 0.001
+}
+
+// Документы для выгрузки по документу из биллинговой программы. Если у помещения несколько
+// действующих лицевых счетов с долями и ни один из них не указан в документе, то документ
+// делится на документы по каждому лицевому счёту: суммы, включая итоговую, объёмы и площади -
+// пропорционально долям (остаток от округления - в последнем документе).
+// Иначе возвращается исходный документ.
+func (reg *Registry) Split(doc *PaymentDocument) []*PaymentDocument {
+	accounts := reg.Accounts[doc.PremisesID]
+	if doc.ZhkuID != "" || len(accounts) < 2 || !sharesComplete(accounts) {
+		return []*PaymentDocument{doc}
+	}
+
+	docs := make([]*PaymentDocument, len(accounts))
+	for i, acc := range accounts {
+		d := *doc
+		d.Services = append([]ServiceLine(nil), doc.Services...)
+		d.Penalties = append([]Penalty(nil), doc.Penalties...)
+		d.ZhkuID = acc.ID
+		d.Account = acc.Account
+		if d.Account == "" {
+			d.Account = fmt.Sprintf("%s-%d", doc.Account, i+1)
+		}
+		d.Area *= acc.Share
+		d.LivingArea *= acc.Share
+		d.HeatedArea *= acc.Share
+		for j := range d.Services {
+			d.Services[j].Volume *= acc.Share
+		}
+		docs[i] = &d
+	}
+
+	fields := make([][]*Money, len(docs))
+	for i, d := range docs {
+		fields[i] = d.moneyFields()
+	}
+	for k, total := range doc.moneyFields() {
+		rest := *total
+		for i, acc := range accounts {
+			part := rest
+			if i < len(accounts)-1 {
+				part = total.Mul(acc.Share)
+			}
+			*fields[i][k] = part
+			rest -= part
+		}
+	}
+	return docs
+}
+
+// Суммы документа, которые делятся между лицевыми счетами
+func (doc *PaymentDocument) moneyFields() []*Money {
+	res := []*Money{&doc.Total, &doc.Balance, &doc.Maintenance.Total,
+		&doc.CapitalRepair.Charged, &doc.CapitalRepair.Recalculation, &doc.CapitalRepair.Total}
+	for i := range doc.Services {
+		line := &doc.Services[i]
+		res = append(res, &line.Charged, &line.Recalculation, &line.Total)
+	}
+	for i := range doc.Penalties {
+		res = append(res, &doc.Penalties[i].Amount)
+	}
+	return res
+}
@@ -0,0 +1,128 @@
+package pldoc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseShare(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want float64
+	}{
+		{"", 0},
+		{"1", 1},
+		{"1/2", 0.5},
+		{" 1 / 3 ", 1.0 / 3},
+		{"0,25", 0.25},
+		{"0.75", 0.75},
+		{"50%", 0.5},
+		{"12,5 %", 0.125},
+	} {
+		got, err := ParseShare(tt.s)
+		if err != nil {
+			t.Errorf("ParseShare(%q): unexpected error %v", tt.s, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ParseShare(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+	for _, s := range []string{"0", "1/0", "3/2", "150%", "-1/2", "abc", "1/x"} {
+		if got, err := ParseShare(s); err == nil {
+			t.Errorf("ParseShare(%q) = %v, want error", s, got)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	reg := NewRegistry()
+	reg.Accounts["P1"] = []ZhkuAccount{
+		{ID: "Z1", Account: "100", Share: 1.0 / 3},
+		{ID: "Z2", Share: 1.0 / 3},
+		{ID: "Z3", Account: "102", Share: 1.0 / 3},
+	}
+	doc := &PaymentDocument{
+		Account:    "555",
+		PremisesID: "P1",
+		Balance:    -100,
+		Services: []ServiceLine{
+			{Name: "a", Volume: 30, Price: 3333, Charged: 100000, Recalculation: -1, Total: 99999},
+		},
+		Penalties:     []Penalty{{Amount: 10}},
+		CapitalRepair: CapitalRepair{Charged: 200, Total: 200},
+		// итоговая сумма отличается от суммы строк (100209) и делится отдельно
+		Total: 100210,
+	}
+	doc.Area = 45
+
+	docs := reg.Split(doc)
+	if len(docs) != 3 {
+		t.Fatalf("Split: %d documents, want 3", len(docs))
+	}
+	for i, want := range []struct {
+		zhkuID, account string
+		total, charged  Money
+		penalty         Money
+	}{
+		{"Z1", "100", 33403, 33333, 3},
+		{"Z2", "555-2", 33403, 33333, 3},
+		{"Z3", "102", 33404, 33334, 4},
+	} {
+		d := docs[i]
+		if d.ZhkuID != want.zhkuID || d.Account != want.account {
+			t.Errorf("doc %d: ZhkuID %s, Account %s, want %s, %s", i, d.ZhkuID, d.Account, want.zhkuID, want.account)
+		}
+		if d.Total != want.total || d.Services[0].Charged != want.charged || d.Penalties[0].Amount != want.penalty {
+			t.Errorf("doc %d: total %s, charged %s, penalty %s, want %s, %s, %s", i,
+				d.Total, d.Services[0].Charged, d.Penalties[0].Amount, want.total, want.charged, want.penalty)
+		}
+		if math.Abs(d.Area-15) > 1e-9 || math.Abs(d.Services[0].Volume-10) > 1e-9 {
+			t.Errorf("doc %d: area %v, volume %v, want 15, 10", i, d.Area, d.Services[0].Volume)
+		}
+	}
+
+	// суммы документов по лицевым счетам совпадают с суммами исходного документа
+	var total, balance, recalculation, capitalRepair Money
+	for _, d := range docs {
+		total += d.Total
+		balance += d.Balance
+		recalculation += d.Services[0].Recalculation
+		capitalRepair += d.CapitalRepair.Total
+	}
+	if total != doc.Total || balance != doc.Balance || recalculation != doc.Services[0].Recalculation ||
+		capitalRepair != doc.CapitalRepair.Total {
+		t.Errorf("sums of split documents %s, %s, %s, %s differ from original %s, %s, %s, %s",
+			total, balance, recalculation, capitalRepair,
+			doc.Total, doc.Balance, doc.Services[0].Recalculation, doc.CapitalRepair.Total)
+	}
+	if doc.Services[0].Charged != 100000 || doc.Area != 45 {
+		t.Errorf("original document changed")
+	}
+}
+
+func TestSplitSelectedAccount(t *testing.T) {
+	reg := NewRegistry()
+	reg.Rooms[RoomID{Number: 1}] = "P1"
+	reg.Accounts["P1"] = []ZhkuAccount{
+		{ID: "Z1", Account: "100", Share: 0.5},
+		{ID: "Z2", Account: "101", Share: 0.5},
+	}
+	doc := &PaymentDocument{Account: "101", Room: RoomID{Number: 1}, Total: 1000}
+	reg.Resolve(doc)
+	if doc.ZhkuID != "Z2" {
+		t.Errorf("ZhkuID %s, want Z2", doc.ZhkuID)
+	}
+	if docs := reg.Split(doc); len(docs) != 1 || docs[0] != doc {
+		t.Errorf("document with selected account is split into %d documents", len(docs))
+	}
+}
+
+func TestSplitWithoutShares(t *testing.T) {
+	reg := NewRegistry()
+	reg.Accounts["P1"] = []ZhkuAccount{{ID: "Z1", Share: 0.5}, {ID: "Z2"}}
+	doc := &PaymentDocument{Account: "555", PremisesID: "P1"}
+	if docs := reg.Split(doc); len(docs) != 1 {
+		t.Errorf("document without shares is split into %d documents", len(docs))
+	}
+}
@@ -0,0 +1,136 @@
+package pldoc
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/extrame/xls"
+	"github.com/tealeg/xlsx"
+)
+
+// Лист входного документа независимо от формата файла
+type Sheet interface {
+	Name() string
+	MaxRow() int                  // номер последней строки (с 0)
+	Cell(row int, col int) string // текст ячейки в UTF-8 (пустая строка, если ячейки нет)
+}
+
+// Расширения входных файлов, которые могут быть прочитаны
+var InputExtensions = []string{".xls", ".xlsx", ".xlsm"}
+
+// Можно ли прочитать файл (по расширению без учёта регистра)
+func IsInputFile(fileName string) bool {
+	ext := filepath.Ext(fileName)
+	for _, v := range InputExtensions {
+		if strings.EqualFold(ext, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Открывает книгу Excel (.xls, .xlsx, .xlsm) и возвращает её листы
+func OpenSheets(fileName string) ([]Sheet, error) {
+	var sheets []Sheet
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xls":
+		xlBook, err := xls.Open(fileName, "win1251")
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < xlBook.NumSheets(); i++ {
+			if xlSheet := xlBook.GetSheet(i); xlSheet != nil {
+				sheets = append(sheets, xlsSheet{xlSheet})
+			}
+		}
+	case ".xlsx", ".xlsm":
+		xlFile, err := xlsx.OpenFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		for _, xlSheet := range xlFile.Sheets {
+			sheets = append(sheets, xlsxSheet{xlSheet})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file type '%s'", filepath.Ext(fileName))
+	}
+	return sheets, nil
+}
+
+// Лист .xls
+type xlsSheet struct {
+	sheet *xls.WorkSheet
+}
+
+func (s xlsSheet) Name() string {
+	return s.sheet.Name
+}
+
+func (s xlsSheet) MaxRow() int {
+	return int(s.sheet.MaxRow)
+}
+
+func (s xlsSheet) Cell(row int, col int) (resStr string) {
+	if row < 0 || row > int(s.sheet.MaxRow) {
+		return ""
+	}
+	// xls.WorkSheet.Row паникует на отсутствующих (пустых) строках
+	defer func() {
+		if recover() != nil {
+			resStr = ""
+		}
+	}()
+	return toUTF(s.sheet.Row(row).Col(col))
+}
+
+// Лист .xlsx (.xlsm)
+type xlsxSheet struct {
+	sheet *xlsx.Sheet
+}
+
+func (s xlsxSheet) Name() string {
+	return s.sheet.Name
+}
+
+func (s xlsxSheet) MaxRow() int {
+	return len(s.sheet.Rows) - 1
+}
+
+func (s xlsxSheet) Cell(row int, col int) string {
+	if row < 0 || row >= len(s.sheet.Rows) || s.sheet.Rows[row] == nil {
+		return ""
+	}
+	cells := s.sheet.Rows[row].Cells
+	if col < 0 || col >= len(cells) || cells[col] == nil {
+		return ""
+	}
+	return cells[col].String()
+}
+
+// Строки листа from..to как отдельный лист (номера строк считаются от from)
+type sheetBlock struct {
+	Sheet
+	from int
+	to   int
+}
+
+func (s *sheetBlock) MaxRow() int {
+	return s.to - s.from
+}
+
+// Переводит место ошибки в блоке в место на листе
+func (s *sheetBlock) locate(e *ParseError) {
+	e.Sheet = s.Name()
+	if e.Row >= 0 {
+		e.Row += s.from
+	}
+}
+
+func (s *sheetBlock) Cell(row int, col int) string {
+	if row < 0 || row > s.to-s.from {
+		return ""
+	}
+	return s.Sheet.Cell(s.from+row, col)
+}
@@ -0,0 +1,261 @@
+package pldoc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+// Заголовок, по которому определяются строки шапки на каждом листе шаблона
+const anchorHeader = "Номер платежного документа"
+
+// Сколько строк от начала листа просматривается в поисках шапки
+const maxHeaderRows = 30
+
+// Лист шаблона импорта ПД с многострочной шапкой
+type templateSheet struct {
+	sheet        *xlsx.Sheet
+	columns      []int // номер колонки листа для каждой колонки шаблона (-1, если её нет на листе)
+	firstDataRow int   // первая строка данных под шапкой
+	styles       map[int]*xlsx.Style
+}
+
+// Строка данных листа шаблона
+type templateRow struct {
+	ts  *templateSheet
+	row *xlsx.Row
+}
+
+// Ячейка строки для колонки шаблона. Если колонки нет на листе, то возвращается
+// ячейка, не связанная с листом (записанное в неё значение никуда не попадёт).
+func (r templateRow) cell(column int) *xlsx.Cell {
+	col := r.ts.columns[column]
+	if col < 0 {
+		return new(xlsx.Cell)
+	}
+	for len(r.row.Cells) <= col {
+		r.row.AddCell()
+	}
+	cell := r.row.Cells[col]
+	if style, ok := r.ts.styles[col]; ok {
+		cell.SetStyle(style)
+	}
+	return cell
+}
+
+// Создаёт лист с однострочной шапкой из названий колонок
+func newTemplateSheet(xlFile *xlsx.File, title string, headers [][]string) (*templateSheet, error) {
+	sheet, err := xlFile.AddSheet(title)
+	if err != nil {
+		return nil, fmt.Errorf("add sheet %s: %v", title, err)
+	}
+
+	ts := &templateSheet{sheet: sheet, firstDataRow: 1, columns: make([]int, len(headers))}
+	xlRow := sheet.AddRow()
+	for i, v := range headers {
+		xlRow.AddCell().SetValue(strings.Join(v, ": "))
+		ts.columns[i] = i
+	}
+	return ts, nil
+}
+
+// Находит на листе шапку, первую строку данных и колонки шаблона по их заголовкам.
+// Возвращает также список колонок, не найденных на листе.
+func analyzeTemplateSheet(sheet *xlsx.Sheet, headers [][]string) (*templateSheet, []string, error) {
+	// строка, в которой есть заголовок номера платёжного документа
+	anchorRow := -1
+	anchor := normalizeHeader(anchorHeader)
+	for r := 0; r < len(sheet.Rows) && r < maxHeaderRows && anchorRow < 0; r++ {
+		for _, cell := range sheet.Rows[r].Cells {
+			if strings.Contains(normalizeHeader(cell.String()), anchor) {
+				anchorRow = r
+				break
+			}
+		}
+	}
+	if anchorRow < 0 {
+		return nil, nil, fmt.Errorf("sheet %s: header '%s' not found", sheet.Name, anchorHeader)
+	}
+
+	// шапка заканчивается на нижней границе объединённых по вертикали ячеек этой строки
+	headerEnd := anchorRow
+	for _, cell := range sheet.Rows[anchorRow].Cells {
+		if anchorRow+cell.VMerge > headerEnd {
+			headerEnd = anchorRow + cell.VMerge
+		}
+	}
+
+	// полные заголовки колонок (сверху вниз) с учётом объединённых по горизонтали ячеек
+	maxCol := 0
+	for r := 0; r <= headerEnd && r < len(sheet.Rows); r++ {
+		if len(sheet.Rows[r].Cells) > maxCol {
+			maxCol = len(sheet.Rows[r].Cells)
+		}
+	}
+	colHeaders := make([][]string, maxCol)
+	for r := 0; r <= headerEnd && r < len(sheet.Rows); r++ {
+		cells := sheet.Rows[r].Cells
+		for c := 0; c < len(cells); c++ {
+			text := normalizeHeader(cells[c].String())
+			if text == "" {
+				continue
+			}
+			for i := c; i <= c+cells[c].HMerge && i < maxCol; i++ {
+				colHeaders[i] = append(colHeaders[i], text)
+			}
+		}
+	}
+
+	ts := &templateSheet{
+		sheet:        sheet,
+		columns:      matchTemplateColumns(colHeaders, headers),
+		firstDataRow: headerEnd + 1,
+		styles:       make(map[int]*xlsx.Style),
+	}
+
+	// строка с номерами колонок под шапкой
+	if ts.firstDataRow < len(sheet.Rows) && isColumnNumbersRow(sheet.Rows[ts.firstDataRow]) {
+		ts.firstDataRow++
+	}
+
+	// стили ячеек первой строки данных применяются ко всем новым строкам
+	if ts.firstDataRow < len(sheet.Rows) {
+		for c, cell := range sheet.Rows[ts.firstDataRow].Cells {
+			ts.styles[c] = cell.GetStyle()
+		}
+	}
+
+	// пустые (заготовленные) строки в конце листа удаляются, чтобы данные шли сразу под шапкой
+	n := len(sheet.Rows)
+	for n > ts.firstDataRow && isEmptyRow(sheet.Rows[n-1]) {
+		n--
+	}
+	sheet.Rows = sheet.Rows[:n]
+	sheet.MaxRow = n
+
+	var missing []string
+	for i, v := range ts.columns {
+		if v < 0 {
+			missing = append(missing, fmt.Sprintf("%s: %s", sheet.Name, strings.Join(headers[i], ": ")))
+		}
+	}
+	return ts, missing, nil
+}
+
+// Удаляет все строки данных (шапка остаётся)
+func (ts *templateSheet) clear() {
+	if len(ts.sheet.Rows) > ts.firstDataRow {
+		ts.sheet.Rows = ts.sheet.Rows[:ts.firstDataRow]
+		ts.sheet.MaxRow = ts.firstDataRow
+	}
+}
+
+// Сопоставляет колонкам шаблона колонки листа. Колонки ищутся по порядку слева направо,
+// поэтому одинаковые заголовки ("Всего", "Сумма, руб.") различаются по положению.
+// Колонка листа сопоставляется не более чем одной колонке шаблона (-1, если подходящих
+// свободных колонок нет).
+func matchTemplateColumns(colHeaders [][]string, headers [][]string) []int {
+	columns := make([]int, len(headers))
+	used := make([]bool, len(colHeaders))
+	prev := -1
+	for i, v := range headers {
+		fragments := make([]string, len(v))
+		for j := range v {
+			fragments[j] = normalizeHeader(v[j])
+		}
+
+		col := -1
+		for _, from := range []int{prev + 1, 0} {
+			for _, exact := range []bool{true, false} {
+				for c := from; c < len(colHeaders) && col < 0; c++ {
+					if !used[c] && headerMatches(colHeaders[c], fragments, exact) {
+						col = c
+					}
+				}
+				if col >= 0 {
+					break
+				}
+			}
+			if col >= 0 {
+				break
+			}
+		}
+		columns[i] = col
+		if col >= 0 {
+			used[col] = true
+			prev = col
+		}
+	}
+	return columns
+}
+
+// Проверяет, что заголовок колонки содержит все фрагменты по порядку;
+// при exact последний фрагмент должен совпадать с нижней ячейкой заголовка
+func headerMatches(colHeader []string, fragments []string, exact bool) bool {
+	if len(colHeader) == 0 {
+		return false
+	}
+	if exact && colHeader[len(colHeader)-1] != fragments[len(fragments)-1] {
+		return false
+	}
+	full := strings.Join(colHeader, " | ")
+	pos := 0
+	for _, v := range fragments {
+		p := strings.Index(full[pos:], v)
+		if p < 0 {
+			return false
+		}
+		pos += p + len(v)
+	}
+	return true
+}
+
+var (
+	reHeaderHyphen = regexp.MustCompile(`(\pL)-\s*(\pL)`)
+	reHeaderSpace  = regexp.MustCompile(`\s+`)
+)
+
+// Приводит заголовок к виду для сравнения: нижний регистр, без переносов и лишних пробелов
+func normalizeHeader(s string) string {
+	s = strings.ToLower(s)
+	s = strings.Replace(s, "ё", "е", -1)
+	s = strings.Replace(s, "\u00ad", "", -1)
+	s = strings.Replace(s, "\u00a0", " ", -1)
+	s = reHeaderHyphen.ReplaceAllString(s, "$1$2")
+	s = reHeaderSpace.ReplaceAllString(s, " ")
+	return strings.TrimSpace(s)
+}
+
+func isEmptyRow(row *xlsx.Row) bool {
+	if row == nil {
+		return true
+	}
+	for _, cell := range row.Cells {
+		if strings.TrimSpace(cell.String()) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Строка вида "1 2 3 ..." под шапкой
+func isColumnNumbersRow(row *xlsx.Row) bool {
+	if len(row.Cells) == 0 {
+		return false
+	}
+	count := 0
+	for _, cell := range row.Cells {
+		s := strings.TrimSpace(cell.String())
+		if s == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(s); err != nil {
+			return false
+		}
+		count++
+	}
+	return count > 1 && strings.TrimSpace(row.Cells[0].String()) == "1"
+}
@@ -0,0 +1,41 @@
+package pldoc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchTemplateColumns(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		colHeaders [][]string
+		headers    [][]string
+		want       []int
+	}{
+		{
+			name:       "same headers by position",
+			colHeaders: [][]string{{"услуга", "всего"}, {"кап. ремонт", "всего"}},
+			headers:    [][]string{{"Услуга", "Всего"}, {"Всего"}},
+			want:       []int{0, 1},
+		},
+		{
+			name:       "column before previous",
+			colHeaders: [][]string{{"тариф"}, {"объем"}},
+			headers:    [][]string{{"Объем"}, {"Тариф"}},
+			want:       []int{1, 0},
+		},
+		{
+			name: "assigned column is not reused",
+			colHeaders: [][]string{
+				{"услуга", "всего начислено за расчетный период"},
+				{"услуга", "перерасчеты"},
+			},
+			headers: [][]string{{"Всего начислено за расчетный период"}, {"Перерасчеты"}, {"Всего"}},
+			want:    []int{0, 1, -1},
+		},
+	} {
+		if got := matchTemplateColumns(tt.colHeaders, tt.headers); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
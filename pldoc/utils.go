@@ -0,0 +1,103 @@
+package pldoc
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// Указатель строк листа: нормализованный текст ячеек по колонкам в порядке строк
+type rowDesc struct {
+	sheet Sheet
+	cols  map[int][]string
+}
+
+// Условие поиска строки по подписи
+type RowQuery struct {
+	Label      string
+	Col        int // колонка с подписью
+	Occurrence int // номер вхождения: 1 (или 0) - первое, 2 - второе, ..., -1 - последнее, -2 - предпоследнее
+	From       int // первая строка, в которой ищется подпись
+	To         int // последняя строка, в которой ищется подпись (0 - до конца листа)
+}
+
+// Сравнение подписей без учёта регистра, пробелов по краям и вида пробелов внутри
+// (strings.Fields разделяет строку в том числе по неразрывным пробелам)
+func normalizeLabel(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func toUTF(inputString string) string {
+
+	inReader := strings.NewReader(inputString)
+	resReader := transform.NewReader(inReader, charmap.Windows1251.NewDecoder())
+	buf, _ := ioutil.ReadAll(resReader)
+	return string(buf) // строка в UTF-8
+}
+
+// Текст ячейки листа в UTF-8 (пустая строка, если строки нет)
+func cellString(sheet Sheet, row int, col int) string {
+	return sheet.Cell(row, col)
+}
+
+func newRowDesc(sheet Sheet) *rowDesc {
+	return &rowDesc{sheet: sheet, cols: make(map[int][]string)}
+}
+
+// Нормализованный текст ячеек колонки (читается при первом обращении)
+func (rd *rowDesc) column(col int) []string {
+	texts, ok := rd.cols[col]
+	if !ok {
+		texts = make([]string, rd.sheet.MaxRow()+1)
+		for i := range texts {
+			texts[i] = normalizeLabel(cellString(rd.sheet, i, col))
+		}
+		rd.cols[col] = texts
+	}
+	return texts
+}
+
+// Номер строки, подходящей под условие (-1, если не найдена)
+func (rd *rowDesc) Find(q RowQuery) int {
+	texts := rd.column(q.Col)
+	label := normalizeLabel(q.Label)
+
+	from, to := q.From, q.To
+	if from < 0 {
+		from = 0
+	}
+	if to <= 0 || to >= len(texts) {
+		to = len(texts) - 1
+	}
+
+	var found []int
+	for i := from; i <= to; i++ {
+		if texts[i] == label {
+			found = append(found, i)
+		}
+	}
+
+	n := q.Occurrence
+	switch {
+	case n == 0:
+		n = 1
+	case n < 0:
+		n = len(found) + n + 1
+	}
+	if n < 1 || n > len(found) {
+		return -1
+	}
+	return found[n-1]
+}
+
+func FileExists(fileName string) bool {
+	if _, err := os.Stat(fileName); err != nil {
+		if os.IsNotExist(err) {
+			return false
+		}
+	}
+	return true
+}
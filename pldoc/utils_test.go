@@ -0,0 +1,74 @@
+package pldoc
+
+import "testing"
+
+// Лист в памяти для тестов: строки - ячейки по колонкам
+type memSheet [][]string
+
+func (s memSheet) Name() string { return "test" }
+
+func (s memSheet) MaxRow() int { return len(s) - 1 }
+
+func (s memSheet) Cell(row int, col int) string {
+	if row < 0 || row >= len(s) || col < 0 || col >= len(s[row]) {
+		return ""
+	}
+	return s[row][col]
+}
+
+func TestNormalizeLabel(t *testing.T) {
+	for _, tt := range []struct {
+		s, want string
+	}{
+		{"Итого", "итого"},
+		{"  ИТОГО  к оплате ", "итого к оплате"},
+		{"Долг/ переплата", "долг/ переплата"},
+		{"\tУслуга\n", "услуга"},
+	} {
+		if got := normalizeLabel(tt.s); got != tt.want {
+			t.Errorf("normalizeLabel(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestRowDescFind(t *testing.T) {
+	sheet := memSheet{
+		{"Платежный документ"}, // 0
+		{"Услуга", "Тариф"},    // 1
+		{"охрана"},             // 2
+		{" ИТОГО "},            // 3
+		{"Отчисления на капитальный ремонт"}, // 4
+		{"Итого"},     // 5
+		{"", "Итого"}, // 6
+		{"итого  "},   // 7
+	}
+	rows := newRowDesc(sheet)
+	for _, tt := range []struct {
+		name string
+		q    RowQuery
+		want int
+	}{
+		{"first", RowQuery{Label: "Итого"}, 3},
+		{"occurrence 1", RowQuery{Label: "итого", Occurrence: 1}, 3},
+		{"second", RowQuery{Label: "Итого", Occurrence: 2}, 5},
+		{"third", RowQuery{Label: "Итого", Occurrence: 3}, 7},
+		{"last", RowQuery{Label: "Итого", Occurrence: -1}, 7},
+		{"second to last", RowQuery{Label: "Итого", Occurrence: -2}, 5},
+		{"beyond last", RowQuery{Label: "Итого", Occurrence: 4}, -1},
+		{"before first", RowQuery{Label: "Итого", Occurrence: -4}, -1},
+		{"from", RowQuery{Label: "Итого", From: 4}, 5},
+		{"from to", RowQuery{Label: "Итого", From: 4, To: 6}, 5},
+		{"last in range", RowQuery{Label: "Итого", Occurrence: -1, To: 6}, 5},
+		{"empty range", RowQuery{Label: "Итого", From: 6, To: 6}, -1},
+		{"to beyond sheet", RowQuery{Label: "Итого", From: 6, To: 100}, 7},
+		{"label column", RowQuery{Label: "итого", Col: 1}, 6},
+		{"other column", RowQuery{Label: "Тариф", Col: 1}, 1},
+		{"not in column 0", RowQuery{Label: "Тариф"}, -1},
+		{"not found", RowQuery{Label: "Долг"}, -1},
+		{"missing column", RowQuery{Label: "Итого", Col: 5}, -1},
+	} {
+		if got := rows.Find(tt.q); got != tt.want {
+			t.Errorf("%s: Find(%+v) = %d, want %d", tt.name, tt.q, got, tt.want)
+		}
+	}
+}
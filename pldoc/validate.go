@@ -0,0 +1,147 @@
+package pldoc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+const sheetTitleProblems = "Ошибки"
+
+// Проблема, найденная при проверке платёжного документа
+type Problem struct {
+	SourceFile string
+	DocNumber  string
+	Room       string
+	Field      string // поле шаблона, которое не удалось заполнить
+	Message    string
+	Warning    bool  // предупреждение (не препятствует выгрузке документа)
+	Err        error // исходная ошибка (для проверки через errors.Is), может отсутствовать
+}
+
+// Проверяет, что для документа найдены Идентификатор помещения и Идентификатор ЖКУ
+func (reg *Registry) Validate(doc *PaymentDocument) []Problem {
+	var problems []Problem
+
+	if doc.PremisesID == "" {
+		problem := Problem{
+			SourceFile: doc.SourceFile,
+			DocNumber:  doc.Number(),
+			Room:       doc.Room.String(),
+			Field:      "Идентификатор помещения",
+			Message:    ErrMissingPremisesID.Error(),
+			Err:        ErrMissingPremisesID,
+		}
+		if ids, ok := reg.DuplicateRooms[doc.Room]; ok {
+			problem.Message = fmt.Sprintf("%s: %s", ErrDuplicatePremises, strings.Join(ids, ", "))
+			problem.Err = ErrDuplicatePremises
+		}
+		problems = append(problems, problem)
+	} else if accounts := reg.Accounts[doc.PremisesID]; doc.ZhkuID == "" && len(accounts) > 1 {
+		problems = append(problems, Problem{
+			SourceFile: doc.SourceFile,
+			DocNumber:  doc.Number(),
+			Room:       doc.Room.String(),
+			Field:      "Идентификатор ЖКУ",
+			Message: fmt.Sprintf("premises %s has several accounts without shares (%s), none matches account %s",
+				doc.PremisesID, accountIDs(accounts), doc.Account),
+			Err: ErrDuplicateAccount,
+		})
+	} else if doc.ZhkuID == "" {
+		problem := Problem{
+			SourceFile: doc.SourceFile,
+			DocNumber:  doc.Number(),
+			Room:       doc.Room.String(),
+			Field:      "Идентификатор ЖКУ",
+			Message:    fmt.Sprintf("premises %s not found in accounts registry", doc.PremisesID),
+			Err:        ErrMissingZhkuID,
+		}
+		if closed, ok := reg.Closed[doc.PremisesID]; ok {
+			problem.Message = fmt.Sprintf("accounts %s of premises %s are closed", accountIDs(closed), doc.PremisesID)
+			problem.Err = ErrClosedAccount
+		}
+		problems = append(problems, problem)
+	}
+	if doc.Area == 0 {
+		problem := doc.warning("Общая площадь для ЛС", ErrAreaNotFound.Error()+" in document and rooms registry")
+		problem.Err = ErrAreaNotFound
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+func (p *Problem) String() string {
+	var parts []string
+	for _, v := range []string{p.SourceFile, p.DocNumber, p.Room, p.Field} {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	res := strings.Join(append(parts, p.Message), ": ")
+	if p.Warning {
+		res = "warning: " + res
+	}
+	return res
+}
+
+var problemColumns = []string{"Тип", "Файл", "Номер платежного документа", "Помещение", "Поле", "Описание"}
+
+func (p *Problem) columns() []string {
+	kind := "Ошибка"
+	if p.Warning {
+		kind = "Предупреждение"
+	}
+	return []string{kind, p.SourceFile, p.DocNumber, p.Room, p.Field, p.Message}
+}
+
+// Сохраняет список проблем в CSV (.csv) или на лист "Ошибки" книги Excel (.xlsx)
+func WriteProblems(fileName string, problems []Problem) error {
+	if strings.EqualFold(filepath.Ext(fileName), ".xlsx") {
+		return writeProblemsXLSX(fileName, problems)
+	}
+	return writeProblemsCSV(fileName, problems)
+}
+
+func writeProblemsCSV(fileName string, problems []Problem) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Comma = ';'
+	w.Write(problemColumns)
+	for i := range problems {
+		w.Write(problems[i].columns())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeProblemsXLSX(fileName string, problems []Problem) error {
+	xlFile := xlsx.NewFile()
+	xlSheet, err := xlFile.AddSheet(sheetTitleProblems)
+	if err != nil {
+		return err
+	}
+
+	xlRow := xlSheet.AddRow()
+	for _, v := range problemColumns {
+		xlRow.AddCell().SetValue(v)
+	}
+	for i := range problems {
+		xlRow = xlSheet.AddRow()
+		for _, v := range problems[i].columns() {
+			xlRow.AddCell().SetValue(v)
+		}
+	}
+	return xlFile.Save(fileName)
+}
@@ -0,0 +1,614 @@
+package pldoc
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/tealeg/xlsx"
+)
+
+const (
+	sheetTitleRooms       = "Разделы 1-2"
+	sheetTitleServices    = "Разделы 3-6"
+	sheetTitlePeni        = "Неустойки"
+	rowCurrentDocumentStr = "Текущий"
+
+	// формат ячейки тарифа: тариф может иметь до шести знаков после запятой
+	priceFormat = "0.00####"
+)
+
+// Колонки листа "Разделы 1-2"
+const (
+	roomsZhkuID = iota
+	roomsDocType
+	roomsDocNumber
+	roomsPeriod
+	roomsArea
+	roomsLivingArea
+	roomsHeatedArea
+	roomsResidents
+	roomsDebt
+	roomsAdvance
+	roomsPaymentsDay
+	roomsBIK
+	roomsBankAccount
+	roomsKrRate
+	roomsKrCharged
+	roomsKrRecalculation
+	roomsKrBenefits
+	roomsKrPaymentOrder
+	roomsKrTotal
+	roomsDocID
+	roomsTotal
+	roomsInfo
+)
+
+// Заголовки колонок листа "Разделы 1-2" (фрагменты заголовков многострочной шапки сверху вниз)
+var roomsHeaders = [][]string{
+	roomsZhkuID:          {"Идентификатор ЖКУ"},
+	roomsDocType:         {"Тип ПД"},
+	roomsDocNumber:       {"Номер платежного документа"},
+	roomsPeriod:          {"Расчетный период"},
+	roomsArea:            {"Общая площадь для ЛС"},
+	roomsLivingArea:      {"Жилая площадь"},
+	roomsHeatedArea:      {"Отапливаемая площадь"},
+	roomsResidents:       {"Количество проживающих"},
+	roomsDebt:            {"Задолженность за предыдущие периоды"},
+	roomsAdvance:         {"Аванс на начало расчетного периода"},
+	roomsPaymentsDay:     {"Учтены платежи, поступившие до указанного числа"},
+	roomsBIK:             {"БИК банка"},
+	roomsBankAccount:     {"Расчетный счет"},
+	roomsKrRate:          {"Размер взноса на кв.м"},
+	roomsKrCharged:       {"Всего начислено за расчетный период"},
+	roomsKrRecalculation: {"Перерасчеты всего"},
+	roomsKrBenefits:      {"Льготы, субсидии"},
+	roomsKrPaymentOrder:  {"Порядок расчетов"},
+	roomsKrTotal:         {"Итого к оплате за расчетный период"},
+	roomsDocID:           {"Идентификатор платежного документа"},
+	roomsTotal:           {"Всего"},
+	roomsInfo:            {"Дополнительная информация"},
+}
+
+// Колонки листа "Разделы 3-6"
+const (
+	servicesDocNumber = iota
+	servicesName
+	servicesIndMethod
+	servicesIndVolume
+	servicesOiMethod
+	servicesOiVolume
+	servicesPrice
+	servicesCharged
+	servicesCoefficient
+	servicesCoefficientExcess
+	servicesRecalculation
+	servicesBenefits
+	servicesPaymentOrder
+	servicesIndNorm
+	servicesOiNorm
+	servicesIndReading
+	servicesOiReading
+	servicesHouseIndVolume
+	servicesHouseOiVolume
+	servicesRecalculationBasis
+	servicesRecalculationSum
+	servicesInstallmentPeriod
+	servicesInstallmentPrevious
+	servicesInstallmentPercentSum
+	servicesInstallmentPercent
+	servicesTotalWithInstallment
+	servicesTotal
+	servicesTotalInd
+	servicesTotalOi
+)
+
+// Заголовки колонок листа "Разделы 3-6"
+var servicesHeaders = [][]string{
+	servicesDocNumber:             {"Номер платежного документа"},
+	servicesName:                  {"Услуга"},
+	servicesIndMethod:             {"индивидуальное потребление", "Способ определения объемов КУ"},
+	servicesIndVolume:             {"индивидуальное потребление", "Объем, площадь, количество"},
+	servicesOiMethod:              {"при содержании общего имущества", "Способ определения объемов КУ"},
+	servicesOiVolume:              {"при содержании общего имущества", "Объем, площадь, количество"},
+	servicesPrice:                 {"Тариф"},
+	servicesCharged:               {"Всего начислено за расчетный период"},
+	servicesCoefficient:           {"Размер повышающего коэффициента"},
+	servicesCoefficientExcess:     {"Размер превышения платы"},
+	servicesRecalculation:         {"Перерасчеты всего"},
+	servicesBenefits:              {"Льготы, субсидии"},
+	servicesPaymentOrder:          {"Порядок расчетов"},
+	servicesIndNorm:               {"Норматив потребления", "в жилых помещениях"},
+	servicesOiNorm:                {"Норматив потребления", "при содержании общего имущества"},
+	servicesIndReading:            {"Текущие показания приборов учета", "индивидуальных"},
+	servicesOiReading:             {"Текущие показания приборов учета", "коллективных"},
+	servicesHouseIndVolume:        {"Суммарный объем коммунальных ресурсов в доме", "в помещениях дома"},
+	servicesHouseOiVolume:         {"Суммарный объем коммунальных ресурсов в доме", "в целях содержания общего имущества"},
+	servicesRecalculationBasis:    {"Основания перерасчетов"},
+	servicesRecalculationSum:      {"Сумма, руб."},
+	servicesInstallmentPeriod:     {"Сумма платы с учетом рассрочки платежа", "от платы за расчетный период"},
+	servicesInstallmentPrevious:   {"Сумма платы с учетом рассрочки платежа", "от платы за предыдущие расчетные периоды"},
+	servicesInstallmentPercentSum: {"Проценты за рассрочку", "руб."},
+	servicesInstallmentPercent:    {"Проценты за рассрочку", "%"},
+	servicesTotalWithInstallment:  {"Сумма к оплате с учетом рассрочки платежа и процентов за рассрочку"},
+	servicesTotal:                 {"Всего"},
+	servicesTotalInd:              {"в т. ч. за ком. усл.", "индивид. потребление"},
+	servicesTotalOi:               {"в т. ч. за ком. усл.", "потребление при содержании общего имущества"},
+}
+
+// Колонки листа "Неустойки"
+const (
+	peniDocNumber = iota
+	peniKind
+	peniBasis
+	peniAmount
+)
+
+// Заголовки колонок листа "Неустойки"
+var peniHeaders = [][]string{
+	peniDocNumber: {"Номер платежного документа"},
+	peniKind:      {"Вид начисления"},
+	peniBasis:     {"Основания начислений"},
+	peniAmount:    {"Сумма"},
+}
+
+// Формирует шаблон импорта платёжных документов в ГИС ЖКХ
+type TemplateWriter struct {
+	fileName string
+	xlFile   *xlsx.File
+
+	rooms    *templateSheet
+	services *templateSheet
+	peni     *templateSheet
+
+	missing []string // колонки, не найденные в шаблоне
+}
+
+// Открывает файл шаблона. Если его нет, то он создаётся из официального шаблона
+// импорта ПД baseTemplate, а если и он не задан - пустым.
+func OpenTemplate(excelTemplate string, baseTemplate string) (*TemplateWriter, error) {
+	if !FileExists(excelTemplate) {
+		return NewTemplate(excelTemplate, baseTemplate)
+	}
+
+	xlFile, err := xlsx.OpenFile(excelTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return newTemplateWriter(excelTemplate, xlFile)
+}
+
+// Создаёт чистый шаблон (существующий файл будет перезаписан при сохранении):
+// из официального шаблона импорта ПД baseTemplate с сохранением его шапки, стилей,
+// проверок данных и справочников, а если он не задан - пустой шаблон с однострочной шапкой
+func NewTemplate(excelTemplate string, baseTemplate string) (*TemplateWriter, error) {
+	if baseTemplate != "" {
+		xlFile, err := xlsx.OpenFile(baseTemplate)
+		if err != nil {
+			return nil, err
+		}
+		w, err := newTemplateWriter(excelTemplate, xlFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", baseTemplate, err)
+		}
+		w.rooms.clear()
+		w.services.clear()
+		w.peni.clear()
+		return w, nil
+	}
+
+	var err error
+	w := &TemplateWriter{fileName: excelTemplate, xlFile: xlsx.NewFile()}
+	if w.rooms, err = newTemplateSheet(w.xlFile, sheetTitleRooms, roomsHeaders); err != nil {
+		return nil, err
+	}
+	if w.services, err = newTemplateSheet(w.xlFile, sheetTitleServices, servicesHeaders); err != nil {
+		return nil, err
+	}
+	if w.peni, err = newTemplateSheet(w.xlFile, sheetTitlePeni, peniHeaders); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func newTemplateWriter(excelTemplate string, xlFile *xlsx.File) (*TemplateWriter, error) {
+	var (
+		missing []string
+		err     error
+	)
+
+	w := &TemplateWriter{fileName: excelTemplate, xlFile: xlFile}
+
+	sheetRooms := xlFile.Sheet[sheetTitleRooms]
+	sheetServices := xlFile.Sheet[sheetTitleServices]
+	sheetPeni := xlFile.Sheet[sheetTitlePeni]
+	if sheetRooms == nil || sheetServices == nil || sheetPeni == nil {
+		return nil, fmt.Errorf("%s: invalid structure", excelTemplate)
+	}
+
+	if w.rooms, missing, err = analyzeTemplateSheet(sheetRooms, roomsHeaders); err != nil {
+		return nil, err
+	}
+	w.missing = append(w.missing, missing...)
+	if w.services, missing, err = analyzeTemplateSheet(sheetServices, servicesHeaders); err != nil {
+		return nil, err
+	}
+	w.missing = append(w.missing, missing...)
+	if w.peni, missing, err = analyzeTemplateSheet(sheetPeni, peniHeaders); err != nil {
+		return nil, err
+	}
+	w.missing = append(w.missing, missing...)
+	return w, nil
+}
+
+// Колонки, которые не удалось найти в шапке шаблона (значения для них не записываются)
+func (w *TemplateWriter) MissingColumns() []string {
+	return w.missing
+}
+
+// Сохраняет шаблон
+func (w *TemplateWriter) Save() error {
+	parts, err := w.xlFile.MarshallParts()
+	if err != nil {
+		return err
+	}
+	// библиотека xlsx записывает все листы видимыми, служебные листы шаблона надо снова скрыть
+	parts["xl/workbook.xml"] = restoreHiddenSheets(parts["xl/workbook.xml"], w.xlFile.Sheets)
+
+	f, err := os.Create(w.fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zipWriter := zip.NewWriter(f)
+	for partName, part := range parts {
+		pw, err := zipWriter.Create(partName)
+		if err != nil {
+			return err
+		}
+		if _, err = pw.Write([]byte(part)); err != nil {
+			return err
+		}
+	}
+	if err = zipWriter.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func restoreHiddenSheets(workbookXML string, sheets []*xlsx.Sheet) string {
+	for _, sheet := range sheets {
+		if !sheet.Hidden {
+			continue
+		}
+		var name bytes.Buffer
+		xml.EscapeText(&name, []byte(sheet.Name))
+		re := regexp.MustCompile(`(<sheet name="` + regexp.QuoteMeta(name.String()) + `"[^>]*? state=")visible(")`)
+		workbookXML = re.ReplaceAllString(workbookXML, "${1}hidden${2}")
+	}
+	return workbookXML
+}
+
+// Записывает платёжный документ во все листы шаблона. Если документ с таким номером
+// уже есть в шаблоне, то его строки заменяются новыми (на том же месте листа).
+func (w *TemplateWriter) Write(doc *PaymentDocument) {
+	docNumber := doc.Number()
+
+	rooms := w.rooms.replaceDocumentRows(roomsDocNumber, docNumber)
+	services := w.services.replaceDocumentRows(servicesDocNumber, docNumber)
+	peni := w.peni.replaceDocumentRows(peniDocNumber, docNumber)
+
+	w.writeRoom(rooms, doc)
+	for i := range doc.Services {
+		if doc.Services[i].Group == GroupMaintenance {
+			// выводится в итоговой строке по плате за содержание жилого помещения
+			continue
+		}
+		w.writeService(services, docNumber, &doc.Services[i])
+	}
+	w.writeMaintenance(services, docNumber, &doc.Maintenance)
+	for i := range doc.Penalties {
+		w.writePenalty(peni, docNumber, &doc.Penalties[i])
+	}
+}
+
+// Место листа, куда добавляются строки документа
+type sheetCursor struct {
+	ts  *templateSheet
+	pos int // -1 - в конец листа
+}
+
+func (c *sheetCursor) AddRow() templateRow {
+	if c.pos < 0 {
+		return templateRow{ts: c.ts, row: c.ts.sheet.AddRow()}
+	}
+	row, _ := c.ts.sheet.AddRowAtIndex(c.pos)
+	c.pos++
+	return templateRow{ts: c.ts, row: row}
+}
+
+// Удаляет из листа строки документа и возвращает место для записи новых строк:
+// на месте первой удалённой строки или в конце листа, если строк документа не было
+func (ts *templateSheet) replaceDocumentRows(column int, docNumber string) *sheetCursor {
+	cursor := &sheetCursor{ts: ts, pos: -1}
+	col := ts.columns[column]
+	sheet := ts.sheet
+	if col < 0 || len(sheet.Rows) <= ts.firstDataRow {
+		return cursor
+	}
+
+	rows := sheet.Rows[:ts.firstDataRow]
+	for _, row := range sheet.Rows[ts.firstDataRow:] {
+		if row != nil && col < len(row.Cells) && row.Cells[col].String() == docNumber {
+			if cursor.pos < 0 {
+				cursor.pos = len(rows)
+			}
+			continue
+		}
+		rows = append(rows, row)
+	}
+	for i := len(rows); i < len(sheet.Rows); i++ {
+		sheet.Rows[i] = nil
+	}
+	sheet.Rows = rows
+	sheet.MaxRow = len(rows)
+	return cursor
+}
+
+func (w *TemplateWriter) writeRoom(rows *sheetCursor, doc *PaymentDocument) {
+	// формируем строку с описанием платёжного документа
+	xlRoomsRow := rows.AddRow()
+	// Идентификатор ЖКУ
+	xlRoomsRow.cell(roomsZhkuID).SetValue(doc.ZhkuID)
+	// Тип ПД
+	xlRoomsRow.cell(roomsDocType).SetValue(rowCurrentDocumentStr)
+	// Номер платежного документа
+	xlRoomsRow.cell(roomsDocNumber).SetValue(doc.Number())
+	// Расчетный период (ММ.ГГГГ)
+	xlRoomsRow.cell(roomsPeriod).SetValue(doc.Period.String())
+	// ============= Раздел 1. Сведения о плательщике. Раздел 2. Информация для внесения платы получателю платежа (получателям платежей). =======
+	// Общая площадь для ЛС
+	xlRoomsRow.cell(roomsArea).SetValue(formatArea(doc.Area))
+	// Жилая площадь
+	xlRoomsRow.cell(roomsLivingArea).SetValue(formatArea(doc.LivingArea))
+	// Отапливаемая площадь
+	xlRoomsRow.cell(roomsHeatedArea).SetValue(formatArea(doc.HeatedArea))
+	// Количество проживающих
+	if doc.Residents > 0 {
+		xlRoomsRow.cell(roomsResidents).SetValue(doc.Residents)
+	} else {
+		xlRoomsRow.cell(roomsResidents).SetValue("")
+	}
+	// Задолженность за предыдущие периоды
+	xlRoomsRow.cell(roomsDebt).SetValue(doc.Debt().String())
+	// Аванс на начало расчетного периода
+	xlRoomsRow.cell(roomsAdvance).SetValue(doc.Advance().String())
+	// Учтены платежи, поступившие до указанного числа расчетного периода включительно
+	xlRoomsRow.cell(roomsPaymentsDay).SetValue(doc.PaymentsDay)
+	// БИК банка
+	xlRoomsRow.cell(roomsBIK).SetValue(doc.BIK)
+	// Расчетный счет
+	xlRoomsRow.cell(roomsBankAccount).SetValue(doc.BankAccount)
+	// ============= Раздел 7. Расчёт размера взноса на капитальный ремонт. Раздел 8. Информация для внесения взноса на капитальный ремонт =========
+	// Размер взноса на кв.м, руб.
+	xlRoomsRow.cell(roomsKrRate).SetValue(doc.CapitalRepair.Rate.String())
+	// Всего начислено за расчетный период, руб.
+	xlRoomsRow.cell(roomsKrCharged).SetValue(doc.CapitalRepair.Charged.String())
+	// Перерасчеты всего, руб.
+	if doc.CapitalRepair.HasRecalculation {
+		xlRoomsRow.cell(roomsKrRecalculation).SetValue(doc.CapitalRepair.Recalculation.String())
+	} else {
+		xlRoomsRow.cell(roomsKrRecalculation).SetValue("")
+	}
+	// Льготы, субсидии, руб.
+	xlRoomsRow.cell(roomsKrBenefits).SetValue("")
+	// Порядок расчетов
+	xlRoomsRow.cell(roomsKrPaymentOrder).SetValue("")
+	// Итого к оплате за расчетный период, руб.
+	xlRoomsRow.cell(roomsKrTotal).SetValue(doc.CapitalRepair.Total.String())
+	// =========================
+	// Идентификатор платежного документа
+	xlRoomsRow.cell(roomsDocID).SetValue("")
+	// Всего
+	xlRoomsRow.cell(roomsTotal).SetValue(doc.Total.String())
+	// Дополнительная информация
+	xlRoomsRow.cell(roomsInfo).SetValue("")
+}
+
+// Площадь с двумя знаками после точки (пустая строка, если неизвестна)
+func formatArea(area float64) string {
+	if area == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(area, 'f', 2, 64)
+}
+
+func (w *TemplateWriter) writeService(rows *sheetCursor, docNumber string, line *ServiceLine) {
+	xlServicesRow := rows.AddRow()
+	// Номер платежного документа
+	xlServicesRow.cell(servicesDocNumber).SetValue(docNumber)
+	// Услуга
+	xlServicesRow.cell(servicesName).SetValue(line.GisName)
+	// индивидуальное потребление: Способ определения объемов КУ
+	if line.Individual() {
+		xlServicesRow.cell(servicesIndMethod).SetValue(line.Method)
+	} else {
+		xlServicesRow.cell(servicesIndMethod).SetValue("")
+	}
+	// индивидуальное потребление: Объем, площадь, количество
+	if line.Individual() {
+		xlServicesRow.cell(servicesIndVolume).SetValue(strconv.FormatFloat(line.Volume, 'f', 2, 64))
+	} else {
+		xlServicesRow.cell(servicesIndVolume).SetValue("")
+	}
+	// потребление при содержании общего имущества: Способ определения объемов КУ
+	if line.Individual() {
+		xlServicesRow.cell(servicesOiMethod).SetValue("")
+	} else {
+		xlServicesRow.cell(servicesOiMethod).SetValue(line.Method)
+	}
+	// потребление при содержании общего имущества: Объем, площадь, количество
+	if !line.Individual() {
+		xlServicesRow.cell(servicesOiVolume).SetValue(strconv.FormatFloat(line.Volume, 'f', 2, 64))
+	} else {
+		xlServicesRow.cell(servicesOiVolume).SetValue("")
+	}
+	// Тариф руб./еди-ница измерения Размер платы на кв. м, руб.
+	xlServicesRow.cell(servicesPrice).SetFloatWithFormat(line.Price.Float(), priceFormat)
+	// Всего начислено за расчетный период, руб.
+	xlServicesRow.cell(servicesCharged).SetFloatWithFormat(line.Charged.Float(), "0.00")
+	// Размер повышающего коэффициента
+	xlServicesRow.cell(servicesCoefficient).SetValue("")
+	// Размер превышения платы, рассчитанной с применением повышающего коэффициента над размером платы, рассчитанной без учета повышающего коэффициента
+	xlServicesRow.cell(servicesCoefficientExcess).SetValue("")
+	// Перерасчеты всего, руб.
+	xlServicesRow.cell(servicesRecalculation).SetFloatWithFormat(line.Recalculation.Float(), "0.00")
+	// Льготы, субсидии, руб.
+	xlServicesRow.cell(servicesBenefits).SetValue("")
+	// Порядок расчетов
+	xlServicesRow.cell(servicesPaymentOrder).SetValue("")
+	// Норматив потребления коммунальных ресурсов: в жилых помеще-ниях
+	if line.Individual() && line.Norm != 0 {
+		xlServicesRow.cell(servicesIndNorm).SetValue(strconv.FormatFloat(line.Norm, 'f', -1, 64))
+	} else {
+		xlServicesRow.cell(servicesIndNorm).SetValue("")
+	}
+	// Норматив потребления коммунальных ресурсов: на потребление при содержании общего имущества
+	if !line.Individual() && line.Norm != 0 {
+		xlServicesRow.cell(servicesOiNorm).SetValue(strconv.FormatFloat(line.Norm, 'f', -1, 64))
+	} else {
+		xlServicesRow.cell(servicesOiNorm).SetValue("")
+	}
+	// Текущие показания приборов учета коммунальных ресурсов: индиви-дуальных (квартир-ных)
+	if line.Individual() && line.HasReading {
+		xlServicesRow.cell(servicesIndReading).SetValue(strconv.FormatFloat(line.Reading, 'f', -1, 64))
+	} else {
+		xlServicesRow.cell(servicesIndReading).SetValue("")
+	}
+	// Текущие показания приборов учета коммунальных ресурсов: коллек-тивных (общедо-мовых)
+	if !line.Individual() && line.HasReading {
+		xlServicesRow.cell(servicesOiReading).SetValue(strconv.FormatFloat(line.Reading, 'f', -1, 64))
+	} else {
+		xlServicesRow.cell(servicesOiReading).SetValue("")
+	}
+	// Суммарный объем коммунальных ресурсов в доме: в помеще-ниях дома
+	xlServicesRow.cell(servicesHouseIndVolume).SetValue("")
+	// Суммарный объем коммунальных ресурсов в доме: в целях содержания общего имущества
+	xlServicesRow.cell(servicesHouseOiVolume).SetValue("")
+	// Основания перерасчетов
+	xlServicesRow.cell(servicesRecalculationBasis).SetValue(line.RecalculationBasis)
+	// Сумма, руб.
+	if line.Recalculation != 0 {
+		xlServicesRow.cell(servicesRecalculationSum).SetFloatWithFormat(line.Recalculation.Float(), "0.00")
+	} else {
+		xlServicesRow.cell(servicesRecalculationSum).SetValue("")
+	}
+	// Сумма платы с учетом рассрочки платежа: от платы за расчетный период
+	xlServicesRow.cell(servicesInstallmentPeriod).SetValue("")
+	// Сумма платы с учетом рассрочки платежа: от платы за предыдущие расчетные периоды
+	xlServicesRow.cell(servicesInstallmentPrevious).SetValue("")
+	// Проценты за рассрочку: руб.
+	xlServicesRow.cell(servicesInstallmentPercentSum).SetFloatWithFormat(0.0, "0.00")
+	// Проценты за рассрочку: %
+	xlServicesRow.cell(servicesInstallmentPercent).SetFloatWithFormat(0.0, "0.00")
+	// Сумма к оплате с учетом рассрочки платежа и процентов за рассрочку, руб.
+	xlServicesRow.cell(servicesTotalWithInstallment).SetFloatWithFormat(line.Total.Float(), "0.00")
+	// Всего
+	if line.Individual() {
+		xlServicesRow.cell(servicesTotal).SetFloatWithFormat(line.Total.Float(), "0.00")
+	} else {
+		xlServicesRow.cell(servicesTotal).SetValue("")
+	}
+	// в т. ч. за ком. усл.: индивид. потребление
+	if line.Individual() && !line.Additional() {
+		xlServicesRow.cell(servicesTotalInd).SetFloatWithFormat(line.Total.Float(), "0.00")
+	} else {
+		xlServicesRow.cell(servicesTotalInd).SetValue("")
+	}
+	// в т. ч. за ком. усл.: потребление при содержании общего имущества
+	if !line.Individual() && !line.Additional() {
+		xlServicesRow.cell(servicesTotalOi).SetFloatWithFormat(line.Total.Float(), "0.00")
+	} else {
+		xlServicesRow.cell(servicesTotalOi).SetValue("")
+	}
+}
+
+// Итоговая строка по Плате за содержание жилого помещения
+func (w *TemplateWriter) writeMaintenance(rows *sheetCursor, docNumber string, m *Maintenance) {
+	xlServicesRow := rows.AddRow()
+	// Номер платежного документа
+	xlServicesRow.cell(servicesDocNumber).SetValue(docNumber)
+	// Услуга
+	xlServicesRow.cell(servicesName).SetValue("Плата за содержание жилого помещения")
+	// индивидуальное потребление: Способ определения объемов КУ
+	xlServicesRow.cell(servicesIndMethod).SetValue("")
+	// индивидуальное потребление: Объем, площадь, количество
+	xlServicesRow.cell(servicesIndVolume).SetValue("")
+	// потребление при содержании общего имущества: Способ определения объемов КУ
+	xlServicesRow.cell(servicesOiMethod).SetValue("")
+	// потребление при содержании общего имущества: Объем, площадь, количество
+	xlServicesRow.cell(servicesOiVolume).SetValue("")
+	// Тариф руб./еди-ница измерения Размер платы на кв. м, руб.
+	xlServicesRow.cell(servicesPrice).SetFloatWithFormat(m.Price.Float(), priceFormat)
+	// Всего начислено за расчетный период, руб.
+	xlServicesRow.cell(servicesCharged).SetValue("")
+	// Размер повышающего коэффициента
+	xlServicesRow.cell(servicesCoefficient).SetValue("")
+	// Размер превышения платы, рассчитанной с применением повышающего коэффициента над размером платы, рассчитанной без учета повышающего коэффициента
+	xlServicesRow.cell(servicesCoefficientExcess).SetValue("")
+	// Перерасчеты всего, руб.
+	xlServicesRow.cell(servicesRecalculation).SetValue("")
+	// Льготы, субсидии, руб.
+	xlServicesRow.cell(servicesBenefits).SetValue("")
+	// Порядок расчетов
+	xlServicesRow.cell(servicesPaymentOrder).SetValue("")
+	// Норматив потребления коммунальных ресурсов: в жилых помеще-ниях
+	xlServicesRow.cell(servicesIndNorm).SetValue("")
+	// Норматив потребления коммунальных ресурсов: на потребление при содержании общего имущества
+	xlServicesRow.cell(servicesOiNorm).SetValue("")
+	// Текущие показания приборов учета коммунальных ресурсов: индиви-дуальных (квартир-ных)
+	xlServicesRow.cell(servicesIndReading).SetValue("")
+	// Текущие показания приборов учета коммунальных ресурсов: коллек-тивных (общедо-мовых)
+	xlServicesRow.cell(servicesOiReading).SetValue("")
+	// Суммарный объем коммунальных ресурсов в доме: в помеще-ниях дома
+	xlServicesRow.cell(servicesHouseIndVolume).SetValue("")
+	// Суммарный объем коммунальных ресурсов в доме: в целях содержания общего имущества
+	xlServicesRow.cell(servicesHouseOiVolume).SetValue("")
+	// Основания перерасчетов
+	xlServicesRow.cell(servicesRecalculationBasis).SetValue("")
+	// Сумма, руб.
+	xlServicesRow.cell(servicesRecalculationSum).SetValue("")
+	// Сумма платы с учетом рассрочки платежа: от платы за расчетный период
+	xlServicesRow.cell(servicesInstallmentPeriod).SetValue("")
+	// Сумма платы с учетом рассрочки платежа: от платы за предыдущие расчетные периоды
+	xlServicesRow.cell(servicesInstallmentPrevious).SetValue("")
+	// Проценты за рассрочку: руб.
+	xlServicesRow.cell(servicesInstallmentPercentSum).SetValue("")
+	// Проценты за рассрочку: %
+	xlServicesRow.cell(servicesInstallmentPercent).SetValue("")
+	// Сумма к оплате с учетом рассрочки платежа и процентов за рассрочку, руб.
+	xlServicesRow.cell(servicesTotalWithInstallment).SetValue("")
+	// Всего
+	xlServicesRow.cell(servicesTotal).SetFloatWithFormat(m.Total.Float(), "# ##0,00")
+	// в т. ч. за ком. усл.: индивид. потребление
+	xlServicesRow.cell(servicesTotalInd).SetValue("")
+	// в т. ч. за ком. усл.: потребление при содержании общего имущества
+	xlServicesRow.cell(servicesTotalOi).SetValue("")
+}
+
+func (w *TemplateWriter) writePenalty(rows *sheetCursor, docNumber string, p *Penalty) {
+	xlPeniRow := rows.AddRow()
+	// Номер платежного документа
+	xlPeniRow.cell(peniDocNumber).SetValue(docNumber)
+	// Вид начисления
+	xlPeniRow.cell(peniKind).SetValue(p.Kind)
+	// Основания начислений
+	xlPeniRow.cell(peniBasis).SetValue(p.Basis)
+	// Сумма, руб.
+	xlPeniRow.cell(peniAmount).SetFloatWithFormat(p.Amount.Float(), "0.00")
+}
@@ -0,0 +1,106 @@
+package pldoc
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Документ за сентябрь 2019 г. с заданным количеством услуг и неустоек
+func testDocument(account string, services int, penalties int) *PaymentDocument {
+	doc := &PaymentDocument{
+		Period:  Period{Year: 2019, Month: time.September},
+		Account: account,
+		ZhkuID:  "ZHKU-" + account,
+	}
+	for i := 0; i < services; i++ {
+		doc.Services = append(doc.Services, ServiceLine{
+			Name:    fmt.Sprintf("Услуга %d", i+1),
+			GisName: fmt.Sprintf("Услуга %d", i+1),
+			Group:   GroupIndividual,
+			Charged: Money(100 * (i + 1)),
+			Total:   Money(100 * (i + 1)),
+		})
+	}
+	for i := 0; i < penalties; i++ {
+		doc.Penalties = append(doc.Penalties, Penalty{Kind: "Пени", Amount: Money(10 * (i + 1))})
+	}
+	return doc
+}
+
+// Номера документов в строках данных листа шаблона
+func templateDocNumbers(ts *templateSheet, column int) []string {
+	var res []string
+	col := ts.columns[column]
+	for _, row := range ts.sheet.Rows[ts.firstDataRow:] {
+		if row == nil || col >= len(row.Cells) {
+			res = append(res, "")
+			continue
+		}
+		res = append(res, row.Cells[col].String())
+	}
+	return res
+}
+
+func TestTemplateWriterRewrite(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "template.xlsx")
+
+	w, err := NewTemplate(fileName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(testDocument("1", 1, 1))
+	w.Write(testDocument("2", 2, 2))
+	w.Write(testDocument("3", 1, 1))
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// документ 2 выгружается повторно с другим количеством строк
+	if w, err = OpenTemplate(fileName, ""); err != nil {
+		t.Fatal(err)
+	}
+	w.Write(testDocument("2", 3, 1))
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+	// и ещё раз без изменений
+	if w, err = OpenTemplate(fileName, ""); err != nil {
+		t.Fatal(err)
+	}
+	w.Write(testDocument("2", 3, 1))
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if w, err = OpenTemplate(fileName, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		ts     *templateSheet
+		column int
+		want   []string
+	}{
+		{w.rooms, roomsDocNumber, []string{"19091", "19092", "19093"}},
+		// услуги и итоговая строка платы за содержание
+		{w.services, servicesDocNumber, []string{"19091", "19091",
+			"19092", "19092", "19092", "19092", "19093", "19093"}},
+		{w.peni, peniDocNumber, []string{"19091", "19092", "19093"}},
+	} {
+		if got := templateDocNumbers(tt.ts, tt.column); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sheet %s: got %v, want %v", tt.ts.sheet.Name, got, tt.want)
+		}
+	}
+
+	// строки документа записаны заново, а не добавлены к старым
+	col := w.services.columns[servicesName]
+	var names []string
+	for _, row := range w.services.sheet.Rows[w.services.firstDataRow+2 : w.services.firstDataRow+5] {
+		names = append(names, row.Cells[col].String())
+	}
+	if want := []string{"Услуга 1", "Услуга 2", "Услуга 3"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("services of rewritten document: got %v, want %v", names, want)
+	}
+}